@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+)
+
+// loadCompositions reads and parses the composition files bundled with an XRD
+func loadCompositions(files []string) ([]*composition.Composition, error) {
+	comps := make([]*composition.Composition, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read composition: %w", err)
+		}
+		comp, err := composition.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if comp.Metadata == nil {
+			comp.Metadata = make(map[string]interface{})
+		}
+		if comp.Metadata["name"] == nil {
+			comp.Metadata["name"] = file
+		}
+		comps = append(comps, comp)
+	}
+	return comps, nil
+}
+
+// bundleOptions fills in the generator options that describe the bundled compositions:
+// the values they supply for unset XR fields, and what claim consumers get from them
+func bundleOptions(opts *generator.Options, comps []*composition.Composition) {
+	gen := composition.New()
+	defaults := make(map[string][]generator.CompositionDefault)
+
+	for _, comp := range comps {
+		for _, d := range gen.Defaults(comp) {
+			defaults[d.Field] = append(defaults[d.Field], generator.CompositionDefault{
+				Value:       d.Value,
+				Composition: d.Composition,
+			})
+		}
+
+		summary := gen.Summarize(comp)
+		opts.Compositions = append(opts.Compositions, generator.BundledComposition{
+			Name:           summary.Name,
+			Readiness:      summary.Readiness,
+			ConnectionKeys: summary.ConnectionKeys,
+		})
+	}
+
+	opts.CompositionDefaults = defaults
+}
@@ -3,14 +3,29 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/crd"
 	"github.com/spf13/cobra"
 )
 
 var (
 	compOutputFile string
 	showPatches    bool
+	showBase       bool
+
+	groupByProvider bool
+	preserveOrder   bool
+
+	providerDocs    bool
+	providerDocsURL string
+
+	crdDir  string
+	xrdFile string
+
+	compOnlySection string
+	compNoHeading   bool
 )
 
 // compositionCmd represents the composition command
@@ -29,7 +44,25 @@ Examples:
   crossplane-docs composition composition.yaml -o COMPOSITION.md
   
   # Hide patch details
-  crossplane-docs composition composition.yaml --show-patches=false`,
+  crossplane-docs composition composition.yaml --show-patches=false
+
+  # Show details from each resource base, such as the provider config
+  crossplane-docs composition composition.yaml --show-base
+
+  # Flag patches reading XR fields the XRD doesn't declare
+  crossplane-docs composition composition.yaml --xrd xrd.yaml
+
+  # Check patched fields against provider CRDs
+  crossplane-docs composition composition.yaml --crd-dir crds/
+
+  # Print only the field mappings
+  crossplane-docs composition composition.yaml --only=mappings
+
+  # Link managed resource kinds to their provider docs
+  crossplane-docs composition composition.yaml --provider-docs
+
+  # Keep the resources in the order they are declared
+  crossplane-docs composition composition.yaml --preserve-order`,
 	Args: cobra.ExactArgs(1),
 	RunE: runComposition,
 }
@@ -38,7 +71,16 @@ func init() {
 	rootCmd.AddCommand(compositionCmd)
 
 	compositionCmd.Flags().StringVarP(&compOutputFile, "output", "o", "", "Output file (default: stdout)")
+	compositionCmd.Flags().StringVar(&compOnlySection, "only", "", "Render only one section: resources, pipeline, mappings, environment, environment-inputs, connections, credentials, readiness or details")
+	compositionCmd.Flags().BoolVar(&compNoHeading, "no-heading", false, "Drop the heading of the section rendered with --only")
 	compositionCmd.Flags().BoolVar(&showPatches, "show-patches", true, "Show patch details and transformations")
+	compositionCmd.Flags().BoolVar(&groupByProvider, "group-by-provider", false, "Split the managed resources table per provider")
+	compositionCmd.Flags().BoolVar(&preserveOrder, "preserve-order", false, "List resources in declaration order instead of alphabetically")
+	compositionCmd.Flags().StringVar(&xrdFile, "xrd", "", "XRD of the composite, used to flag patches reading fields it doesn't declare")
+	compositionCmd.Flags().StringVar(&crdDir, "crd-dir", "", "Directory of provider CRDs used to check which patched fields the provider requires")
+	compositionCmd.Flags().BoolVar(&providerDocs, "provider-docs", false, "Link managed resource kinds to their provider docs on the Upbound marketplace")
+	compositionCmd.Flags().StringVar(&providerDocsURL, "provider-docs-url", "", "URL template for provider docs links, with {group}, {version} and {kind} placeholders (implies --provider-docs)")
+	compositionCmd.Flags().BoolVar(&showBase, "show-base", false, "Show details from each resource base, such as the provider config")
 }
 
 func runComposition(cmd *cobra.Command, args []string) error {
@@ -49,24 +91,45 @@ func runComposition(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("file not found: %s", compositionFile)
 	}
 
+	opts := composition.Options{
+		ShowPatches: showPatches,
+		ShowBase:    showBase,
+
+		GroupByProvider: groupByProvider,
+		PreserveOrder:   preserveOrder,
+
+		ProviderDocs:    providerDocs,
+		ProviderDocsURL: providerDocsURL,
+
+		Only:      compOnlySection,
+		NoHeading: compNoHeading,
+	}
+
+	if xrdFile != "" {
+		xrds, err := crd.LoadXRD(xrdFile)
+		if err != nil {
+			return fmt.Errorf("failed to load XRD: %w", err)
+		}
+		opts.XRD = xrds
+	}
+
+	if crdDir != "" {
+		crds, err := crd.LoadDir(crdDir)
+		if err != nil {
+			return fmt.Errorf("failed to load provider CRDs: %w", err)
+		}
+		opts.ProviderCRDs = crds
+	}
+
 	// Generate documentation
+	start := time.Now()
 	gen := composition.New()
-	markdown, err := gen.GenerateFromFile(compositionFile, composition.Options{
-		ShowPatches: showPatches,
-	})
+	markdown, err := gen.GenerateFromFile(compositionFile, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate documentation: %w", err)
 	}
+	debugf("%s: generated in %s", compositionFile, time.Since(start).Round(time.Millisecond))
 
 	// Output
-	if compOutputFile == "" {
-		fmt.Println(markdown)
-	} else {
-		if err := os.WriteFile(compOutputFile, []byte(markdown), 0o644); err != nil {
-			return fmt.Errorf("failed to write output file: %w", err)
-		}
-		fmt.Printf("Documentation generated successfully: %s\n", compOutputFile)
-	}
-
-	return nil
+	return newOutput().Write(compOutputFile, markdown)
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffOldVersion string
+	diffNewVersion string
+	diffFormat     string
+	failOnBreaking bool
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [old-xrd-file] [new-xrd-file]",
+	Short: "Report field changes between two XRDs or two versions of one XRD",
+	Long: `Compare the spec and status fields of two XRDs, or of two versions of the
+same XRD, and print added and removed fields, type changes, requiredness
+changes and changed constraints grouped by severity. Removed fields, type
+changes and newly required fields are flagged as potentially breaking.
+
+Examples:
+  # Compare the documented versions of two revisions of an XRD
+  crossplane-docs diff old/xrd.yaml xrd.yaml
+
+  # Compare two versions of the same XRD
+  crossplane-docs diff xrd.yaml --old-version v1alpha1 --new-version v1beta1
+
+  # Fail a CI job on potentially breaking changes
+  crossplane-docs diff old/xrd.yaml xrd.yaml --fail-on-breaking`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffOldVersion, "old-version", "", "Version of the old XRD to compare (default: its referenceable storage version)")
+	diffCmd.Flags().StringVar(&diffNewVersion, "new-version", "", "Version of the new XRD to compare (default: its referenceable storage version)")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text or json")
+	diffCmd.Flags().BoolVar(&failOnBreaking, "fail-on-breaking", false, "Exit with an error when a change is potentially breaking")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	oldFile, newFile := args[0], args[0]
+	if len(args) == 2 {
+		newFile = args[1]
+	} else if diffOldVersion == "" || diffNewVersion == "" {
+		return fmt.Errorf("comparing versions of a single XRD requires --old-version and --new-version")
+	}
+
+	oldXRD, err := loadDiffXRD(oldFile)
+	if err != nil {
+		return err
+	}
+	newXRD, err := loadDiffXRD(newFile)
+	if err != nil {
+		return err
+	}
+
+	result, err := generator.New().DiffVersions(oldXRD, diffOldVersion, newXRD, diffNewVersion)
+	if err != nil {
+		return err
+	}
+
+	switch diffFormat {
+	case "json":
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode diff: %w", err)
+		}
+		fmt.Println(string(out))
+	case "text":
+		printDiff(result)
+	default:
+		return fmt.Errorf("unknown format %q: use text or json", diffFormat)
+	}
+
+	if failOnBreaking && result.Breaking() {
+		return fmt.Errorf("%d potentially breaking change(s)", len(result.BySeverity(generator.SeverityBreaking)))
+	}
+	return nil
+}
+
+// loadDiffXRD reads and parses an XRD to compare
+func loadDiffXRD(file string) (*generator.XRD, error) {
+	data, err := readInput(file)
+	if err != nil {
+		return nil, err
+	}
+	xrd, err := generator.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return xrd, nil
+}
+
+// diffHeadings are the text output headings of each severity
+var diffHeadings = map[generator.Severity]string{
+	generator.SeverityBreaking: "Potentially breaking",
+	generator.SeverityReview:   "Needs review",
+	generator.SeverityInfo:     "Compatible",
+}
+
+// printDiff prints the changes grouped by severity, most severe first
+func printDiff(result generator.DiffResult) {
+	w := os.Stdout
+	if len(result.Changes) == 0 {
+		fmt.Fprintf(w, "No field changes between %s and %s\n", result.OldVersion, result.NewVersion)
+		return
+	}
+
+	fmt.Fprintf(w, "Changes from %s to %s:\n", result.OldVersion, result.NewVersion)
+	for _, severity := range generator.Severities {
+		changes := result.BySeverity(severity)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", diffHeadings[severity])
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %s: %s\n", c.Path, c)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/michielvha/crossplane-docs/pkg/site"
+)
+
+// runDirectory generates one <kind>.md per XRD found in dir, written next to its
+// source or into outDir when set. Files that aren't XRDs are skipped with a warning,
+// and files that fail are reported and left out unless strict is set.
+// When index is set, a combined index linking every page is written under that name.
+func runDirectory(dir, outDir, index string, recursive bool, jobs int, strict bool, opts generator.Options, siteOpts site.Options) error {
+	files, err := findYAMLFiles(dir, recursive)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	gen := generator.New()
+	results := make([]sitePageResult, len(files))
+	forEachConcurrently(len(files), jobs, func(i int) {
+		results[i] = generateSitePage(gen, files[i], false, opts)
+	})
+
+	targets := make(map[string]string) // output file -> source file
+	var errs, failed []error
+	skipped := 0
+	for i, r := range results {
+		switch {
+		case r.err != nil:
+			failed = append(failed, r.err)
+			continue
+		case r.skipped:
+			infof("Skipping %s: not an XRD or CRD", files[i])
+			skipped++
+			continue
+		}
+
+		logPage(files[i], r)
+		target := filepath.Join(outputDirFor(files[i], outDir), site.PageFilename(r.page.Kind))
+		if source, ok := targets[target]; ok {
+			errs = append(errs, fmt.Errorf("%s and %s would both be written to %s", source, files[i], target))
+			continue
+		}
+		targets[target] = files[i]
+	}
+
+	indexFile := ""
+	if index != "" {
+		indexDir := dir
+		if outDir != "" {
+			indexDir = outDir
+		}
+		indexFile = filepath.Join(indexDir, index)
+		if source, ok := targets[indexFile]; ok {
+			errs = append(errs, fmt.Errorf("the docs of %s would overwrite the index %s", source, indexFile))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if err := checkPageErrors(failed, len(targets), strict); err != nil {
+		return err
+	}
+
+	var entries []site.IndexEntry
+	for i, r := range results {
+		if r.page == nil {
+			continue
+		}
+		target := filepath.Join(outputDirFor(files[i], outDir), site.PageFilename(r.page.Kind))
+		if indexFile != "" {
+			link, err := filepath.Rel(filepath.Dir(indexFile), target)
+			if err != nil {
+				return fmt.Errorf("failed to link %s from the index: %w", target, err)
+			}
+			entries = append(entries, site.IndexEntry{
+				Group:       r.page.Group,
+				Kind:        r.page.Kind,
+				Description: r.page.Description,
+				Link:        filepath.ToSlash(link),
+			})
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, []byte(r.page.Content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+
+	if indexFile != "" {
+		if err := os.MkdirAll(filepath.Dir(indexFile), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(indexFile, []byte(site.Index(entries, siteOpts)), 0o644); err != nil {
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+	}
+
+	infof("Documentation generated successfully: %d XRD(s) processed, %d file(s) skipped%s", len(targets), skipped, failedSuffix(len(failed)))
+	return nil
+}
+
+// outputDirFor returns the directory a source file's docs are written to
+func outputDirFor(file, outDir string) string {
+	if outDir != "" {
+		return outDir
+	}
+	return filepath.Dir(file)
+}
@@ -2,22 +2,98 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 
+	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/docdiff"
 	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/michielvha/crossplane-docs/pkg/manifest"
+	"github.com/michielvha/crossplane-docs/pkg/site"
 	"github.com/spf13/cobra"
 )
 
 var (
-	outputFile string
-	showNested bool
+	outputFile   string
+	format       string
+	flavor       string
+	title        string
+	apiVersion   string
+	templateFile string
+	showNested   bool
+	fullPaths    bool
+	toc          bool
+	sortMode     string
+	showRemoved  bool
+	showMatrix   bool
+	allVersions  bool
+	showExamples bool
+	fieldAnchors bool
+	requiredOnly bool
+	excludeTypes []string
+
+	validateExample bool
+	quickstart      bool
+
+	noMetadata       bool
+	metadataPrefixes []string
+	noStatus         bool
+	noExample        bool
+
+	exampleMode string
+
+	quantityFields []string
+
+	statusDescribedOnly   bool
+	statusInclude         []string
+	includeStandardStatus bool
+
+	siteLayout  bool
+	recursive   bool
+	tableFormat string
+	indentStyle string
+	outputDir   string
+	indexFile   string
+	detectDupes bool
+	jobs        int
+	strictFiles bool
+
+	summaryLength int
+	emitMetadata  bool
+
+	checkOnly  bool
+	diffOutput string
+	diffBase   string
+
+	compositionFiles []string
+
+	onlySection string
+	noHeading   bool
+
+	audience string
+
+	fetchTimeout = defaultFetchTimeout
+
+	failOnMissingDescription bool
+)
+
+const (
+	// defaultFetchTimeout bounds fetching an input from a URL
+	defaultFetchTimeout = 30 * time.Second
+	// maxFetchSize caps the size of an input fetched from a URL
+	maxFetchSize = 10 << 20
 )
 
 // xrdCmd represents the xrd command
 var xrdCmd = &cobra.Command{
-	Use:   "xrd [xrd-file]",
+	Use:   "xrd [xrd-file|directory|url|-]",
 	Short: "Generate documentation from an XRD file",
 	Long: `Generate markdown documentation from a Crossplane XRD (CompositeResourceDefinition) YAML file.
+Plain CustomResourceDefinitions are documented the same way.
 
 Examples:
   # Generate docs and print to stdout
@@ -25,9 +101,54 @@ Examples:
 
   # Generate docs and save to file
   crossplane-docs xrd xrd.yaml -o README.md
+
+  # Read the XRD from stdin
+  cat xrd.yaml | crossplane-docs xrd -
+
+  # Fetch the XRD over HTTP(S), e.g. from a remote catalog
+  crossplane-docs xrd https://raw.githubusercontent.com/org/repo/main/apis/xrd.yaml
   
   # Hide nested object structures (if you want a flatter view)
-  crossplane-docs xrd xrd.yaml --show-nested=false`,
+  crossplane-docs xrd xrd.yaml --show-nested=false
+
+  # Export every field as CSV for spreadsheets
+  crossplane-docs xrd xrd.yaml --format=csv -o fields.csv
+
+  # Structured field trees for docs portals
+  crossplane-docs xrd xrd.yaml --format=json
+
+  # Fail in CI when a spec field, nested ones included, has no description
+  crossplane-docs xrd xrd.yaml --fail-on-missing-description
+
+  # Only the fields you must set
+  crossplane-docs xrd xrd.yaml --required-only
+
+  # Lead with a minimal, copy-pasteable manifest instead of the full example
+  crossplane-docs xrd xrd.yaml --quickstart --no-example
+
+  # Show the effective default of each field once its compositions are applied
+  crossplane-docs xrd xrd.yaml --composition aws.yaml --composition gcp.yaml
+
+  # Concise docs for application developers consuming the API
+  crossplane-docs xrd xrd.yaml --composition composition.yaml --audience=consumer
+
+  # Render with your own layout, reusing built-in sections like {{ template "spec" . }}
+  crossplane-docs xrd xrd.yaml --template docs.tmpl
+
+  # Print just the spec table, without its heading, for embedding elsewhere
+  crossplane-docs xrd xrd.yaml --only=spec --no-heading
+
+  # Call out fields dropped since earlier served versions
+  crossplane-docs xrd xrd.yaml --show-removed
+
+  # Fail in CI when README.md is stale, printing a PR-comment friendly changelog
+  crossplane-docs xrd xrd.yaml -o README.md --check --diff-output=markdown --diff-base=old/xrd.yaml
+
+  # Write a <kind>.md next to every XRD below apis/
+  crossplane-docs xrd apis/ --recursive
+
+  # Generate a docs site tree (<group>/<kind>.md) from a directory of XRDs
+  crossplane-docs xrd apis/ --site-layout --output-dir docs/apis`,
 	Args: cobra.ExactArgs(1),
 	RunE: runXRD,
 }
@@ -36,35 +157,321 @@ func init() {
 	rootCmd.AddCommand(xrdCmd)
 
 	xrdCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	xrdCmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, html, csv or json")
+	xrdCmd.Flags().StringVar(&flavor, "flavor", "github", "Markdown flavor: github or techdocs (MkDocs admonitions and anchors)")
+	xrdCmd.Flags().StringVar(&title, "title", "", "Document heading (default: the Kind)")
+	xrdCmd.Flags().StringVar(&apiVersion, "api-version", "", "Version to document (default: the referenceable storage version)")
+	xrdCmd.Flags().StringVar(&templateFile, "template", "", "Go text/template file to render instead of the built-in layout")
+	xrdCmd.Flags().StringVar(&audience, "audience", "platform", "Who the docs are for: platform (everything) or consumer (what to set, when it's ready, what you get)")
+	xrdCmd.Flags().StringVar(&onlySection, "only", "", "Render only one section: spec, status, example, quickstart, validation, ...")
+	xrdCmd.Flags().BoolVar(&noHeading, "no-heading", false, "Drop the heading of the section rendered with --only")
 	xrdCmd.Flags().BoolVar(&showNested, "show-nested", true, "Show nested object structures")
+	xrdCmd.Flags().BoolVar(&fullPaths, "full-paths", false, "Show each field's full dotted path (e.g. spec.parameters.region) instead of an indented name")
+	xrdCmd.Flags().BoolVar(&toc, "toc", false, "Add a linked table of contents below the title")
+	xrdCmd.Flags().StringVar(&sortMode, "sort", "required-first", "Field order: required-first, alphabetical or schema (as declared)")
+	xrdCmd.Flags().BoolVar(&showRemoved, "show-removed", false, "List fields removed since earlier served versions")
+	xrdCmd.Flags().BoolVar(&showMatrix, "version-matrix", false, "Add a matrix comparing fields across served versions")
+	xrdCmd.Flags().BoolVar(&allVersions, "all-versions", false, "Document every version in its own section instead of only the storage version")
+	xrdCmd.Flags().BoolVar(&fieldAnchors, "field-anchors", false, "Add an anchor per field and link field references in descriptions")
+	xrdCmd.Flags().StringVar(&tableFormat, "output-format", "table", "Spec table columns: compact (name, type, required), table or wide (adds path and validation rules)")
+	xrdCmd.Flags().StringVar(&indentStyle, "indent-style", "arrows", "Marker for nested field names: arrows (&nbsp; and ↳), dots or plain (spaces, for renderers without &nbsp;)")
+	xrdCmd.Flags().BoolVar(&showExamples, "show-examples", false, "Add an Example column with per-field schema examples")
+	xrdCmd.Flags().BoolVar(&requiredOnly, "required-only", false, "Only document required spec fields")
+	xrdCmd.Flags().StringSliceVar(&excludeTypes, "exclude-types", nil, "Drop fields of these types from the tables, e.g. object for a scalar-only view")
+	xrdCmd.Flags().BoolVar(&validateExample, "validate-example", false, "Fail if the generated example doesn't conform to the XRD schema")
+	xrdCmd.Flags().BoolVar(&quickstart, "quickstart", false, "Add a Quick Start section with a minimal manifest of only the required fields")
+	xrdCmd.Flags().BoolVar(&noMetadata, "no-metadata", false, "Omit the API group/version/kind block")
+	xrdCmd.Flags().StringSliceVar(&metadataPrefixes, "metadata-prefix", nil, "Show the XRD's labels and annotations whose keys start with these prefixes, e.g. crossplane.io/")
+	xrdCmd.Flags().BoolVar(&noStatus, "no-status", false, "Omit the status fields section")
+	xrdCmd.Flags().StringVar(&exampleMode, "example-mode", "required", "Fields set in the example: required, full (every field) or none")
+	xrdCmd.Flags().BoolVar(&noExample, "no-example", false, "Omit the example section")
+	xrdCmd.Flags().StringSliceVar(&quantityFields, "quantity-fields", generator.DefaultQuantityFields, "Field name keywords documented as Kubernetes quantities")
+	xrdCmd.Flags().BoolVar(&statusDescribedOnly, "status-described-only", false, "Only document status fields that have a description")
+	xrdCmd.Flags().StringSliceVar(&statusInclude, "status-include", nil, "Only document status fields matching these globs (relative to status, e.g. atProvider.*)")
+	xrdCmd.Flags().BoolVar(&includeStandardStatus, "standard-status", false, "Also document the status fields Crossplane adds to every XR and claim (conditions, connectionDetails)")
+	xrdCmd.Flags().BoolVar(&siteLayout, "site-layout", false, "Organise output from a directory of XRDs as <group>/<kind>.md with an _index.md per group")
+	xrdCmd.Flags().BoolVar(&recursive, "recursive", false, "Also document XRDs in subdirectories for directory input (always on with --site-layout)")
+	xrdCmd.Flags().StringVar(&outputDir, "output-dir", "docs/apis", "Output directory for directory input (default without --site-layout: next to each XRD)")
+	xrdCmd.Flags().StringVar(&indexFile, "index", "", "Also write an index linking every generated doc under this name, e.g. index.md (directory input)")
+	xrdCmd.Flags().IntVar(&jobs, "jobs", runtime.GOMAXPROCS(0), "Number of files to generate concurrently for directory input")
+	xrdCmd.Flags().BoolVar(&strictFiles, "strict", false, "Fail the whole run when any file of a directory fails to generate, instead of reporting it and writing the rest")
+	xrdCmd.Flags().IntVar(&summaryLength, "summary-length", 0, "Truncate descriptions in index pages to N characters (0 = no limit)")
+	xrdCmd.Flags().BoolVar(&emitMetadata, "emit-metadata", false, "Write a <kind>.meta.json summary next to each page of the --site-layout output")
+	xrdCmd.Flags().BoolVar(&checkOnly, "check", false, "Check that the --output file is up to date instead of writing it")
+	xrdCmd.Flags().StringVar(&diffOutput, "diff-output", "text", "Diff format printed by --check when docs are stale: text or markdown")
+	xrdCmd.Flags().StringVar(&diffBase, "diff-base", "", "XRD the --output docs were generated from, whose field changes --diff-output=markdown lists")
+	xrdCmd.Flags().StringSliceVar(&compositionFiles, "composition", nil, "Composition files bundled with the XRD; adds an Effective Default column")
+	xrdCmd.Flags().DurationVar(&fetchTimeout, "timeout", defaultFetchTimeout, "Timeout for fetching an XRD from a URL")
+	xrdCmd.Flags().BoolVar(&detectDupes, "detect-dupes", false, "Report every duplicate key with its path before generating")
+	xrdCmd.Flags().BoolVar(&failOnMissingDescription, "fail-on-missing-description", false, "List spec fields without a description and exit non-zero if there are any")
 }
 
 func runXRD(cmd *cobra.Command, args []string) error {
 	xrdFile := args[0]
 
-	// Check if file exists
-	if _, err := os.Stat(xrdFile); os.IsNotExist(err) {
-		return fmt.Errorf("file not found: %s", xrdFile)
+	// Check if file exists; "-" reads the XRD from stdin
+	var info os.FileInfo
+	if xrdFile != "-" && !isURL(xrdFile) {
+		var err error
+		info, err = os.Stat(xrdFile)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file not found: %s", xrdFile)
+		}
+	}
+
+	opts := generator.Options{
+		Format:  format,
+		Flavor:  flavor,
+		Title:   title,
+		Version: apiVersion,
+
+		TemplateFile: templateFile,
+
+		Audience:  audience,
+		Only:      onlySection,
+		NoHeading: noHeading,
+
+		ShowNested:   showNested,
+		TableFormat:  tableFormat,
+		IndentStyle:  indentStyle,
+		FullPaths:    fullPaths,
+		SortMode:     sortMode,
+		TOC:          toc,
+		ShowRemoved:  showRemoved,
+		ShowMatrix:   showMatrix,
+		AllVersions:  allVersions,
+		ShowExamples: showExamples,
+		FieldAnchors: fieldAnchors,
+		RequiredOnly: requiredOnly,
+		ExcludeTypes: excludeTypes,
+
+		ValidateExample: validateExample,
+		Quickstart:      quickstart,
+
+		HideMetadata: noMetadata,
+		HideStatus:   noStatus,
+		HideExample:  noExample,
+
+		ExampleMode: exampleMode,
+
+		MetadataPrefixes: metadataPrefixes,
+
+		QuantityFields: quantityFields,
+
+		StatusDescribedOnly: statusDescribedOnly,
+		StatusInclude:       statusInclude,
+
+		IncludeStandardStatus: includeStandardStatus,
+	}
+
+	// Metadata files are written next to the pages of the site layout only
+	if emitMetadata && (!siteLayout || info == nil || !info.IsDir()) {
+		return fmt.Errorf("--emit-metadata requires --site-layout and a directory of XRDs")
+	}
+
+	if info != nil && info.IsDir() {
+		if title != "" {
+			return fmt.Errorf("--title applies to a single XRD and can't be used with a directory")
+		}
+		if len(compositionFiles) > 0 {
+			return fmt.Errorf("--composition applies to a single XRD and can't be used with a directory")
+		}
+		if failOnMissingDescription {
+			return fmt.Errorf("--fail-on-missing-description applies to a single XRD and can't be used with a directory")
+		}
+		if siteLayout {
+			if indexFile != "" {
+				return fmt.Errorf("--index can't be combined with --site-layout, which writes an _index.md per group")
+			}
+			return runSiteLayout(xrdFile, outputDir, jobs, strictFiles, emitMetadata, opts, site.Options{SummaryLength: summaryLength})
+		}
+
+		// Without a site layout, docs go next to each XRD unless --output-dir is set
+		dir := ""
+		if cmd.Flags().Changed("output-dir") {
+			dir = outputDir
+		}
+		return runDirectory(xrdFile, dir, indexFile, recursive, jobs, strictFiles, opts, site.Options{SummaryLength: summaryLength})
+	}
+	if indexFile != "" {
+		return fmt.Errorf("--index applies to directory input")
+	}
+
+	if len(compositionFiles) > 0 {
+		comps, err := loadCompositions(compositionFiles)
+		if err != nil {
+			return err
+		}
+		bundleOptions(&opts, comps)
+	}
+
+	data, err := readInput(xrdFile)
+	if err != nil {
+		return err
+	}
+
+	if detectDupes {
+		if err := checkDuplicateKeys(xrdFile, data); err != nil {
+			return err
+		}
+	}
+
+	if failOnMissingDescription {
+		if err := checkDescriptions(xrdFile, data, opts); err != nil {
+			return err
+		}
 	}
 
 	// Generate documentation
-	gen := generator.New()
-	markdown, err := gen.GenerateFromFile(xrdFile, generator.Options{
-		ShowNested: showNested,
-	})
+	// A file may also hold the XRD's compositions, separated by "---"
+	start := time.Now()
+	markdown, err := manifest.Generate(data, opts, composition.Options{ShowPatches: true})
 	if err != nil {
 		return fmt.Errorf("failed to generate documentation: %w", err)
 	}
+	debugf("%s: generated in %s", xrdFile, time.Since(start).Round(time.Millisecond))
+
+	if checkOnly {
+		return checkOutput(xrdFile, outputFile, data, markdown)
+	}
 
 	// Output
-	if outputFile == "" {
-		fmt.Println(markdown)
-	} else {
-		if err := os.WriteFile(outputFile, []byte(markdown), 0o644); err != nil {
-			return fmt.Errorf("failed to write output file: %w", err)
+	return newOutput().Write(outputFile, markdown)
+}
+
+// readInput reads the named file, stdin when the name is "-", or the body of
+// an HTTP(S) URL
+func readInput(name string) ([]byte, error) {
+	if isURL(name) {
+		return fetchURL(name)
+	}
+	if name == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
 		}
-		fmt.Printf("Documentation generated successfully: %s\n", outputFile)
+		return data, nil
 	}
 
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+// checkDescriptions reports the spec fields of each XRD in data that have no
+// description to stderr and returns an error if there are any
+func checkDescriptions(file string, data []byte, opts generator.Options) error {
+	docs, err := manifest.Split(data)
+	if err != nil {
+		return err
+	}
+
+	missing := 0
+	for _, doc := range docs {
+		if !generator.IsDefinition(doc.Kind) {
+			continue
+		}
+		xrd, err := generator.Parse(doc.Data)
+		if err != nil {
+			return err
+		}
+		paths, err := generator.New().UndocumentedFields(xrd, opts)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Fprintf(os.Stderr, "%s: %s: missing description\n", file, path)
+		}
+		missing += len(paths)
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%s: %d spec field(s) without a description", file, missing)
+	}
 	return nil
 }
+
+// isURL reports whether an input names an HTTP(S) URL rather than a file
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// fetchURL downloads an input, rejecting error responses and HTML pages such
+// as a repository's file view instead of its raw content
+func fetchURL(url string) ([]byte, error) {
+	timeout := fetchTimeout
+	if timeout <= 0 {
+		timeout = defaultFetchTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, fmt.Errorf("%s returned an HTML page, not XRD YAML: link to the raw file instead", url)
+	}
+
+	// Read one byte past the cap to tell a body of exactly the cap from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > maxFetchSize {
+		return nil, fmt.Errorf("%s is larger than %d MiB", url, maxFetchSize>>20)
+	}
+	return data, nil
+}
+
+// checkOutput compares freshly generated markdown against the existing output file,
+// printing a diff and returning an error when it is stale. The markdown diff lists
+// the field changes from the --diff-base XRD to the XRD in data.
+func checkOutput(source, target string, data []byte, markdown string) error {
+	if target == "" {
+		return fmt.Errorf("--check requires --output")
+	}
+	if diffOutput == "markdown" && diffBase == "" {
+		return fmt.Errorf("--diff-output=markdown requires --diff-base, the XRD the --output docs were generated from")
+	}
+
+	existing, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	want := strings.TrimRight(markdown, "\n") + "\n"
+	if string(existing) == want {
+		infof("Documentation is up to date: %s", target)
+		return nil
+	}
+
+	switch diffOutput {
+	case "markdown":
+		base, err := loadDiffXRD(diffBase)
+		if err != nil {
+			return err
+		}
+		xrd, err := generator.Parse(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		result, err := generator.New().DiffVersions(base, apiVersion, xrd, apiVersion)
+		if err != nil {
+			return err
+		}
+		fmt.Print(docdiff.Markdown(source, result))
+	case "text":
+		fmt.Print(docdiff.Lines(string(existing), want))
+	default:
+		return fmt.Errorf("unknown diff output %q: use text or markdown", diffOutput)
+	}
+
+	return fmt.Errorf("documentation is out of date: %s", target)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchURLSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := maxFetchSize
+		if r.URL.Path == "/large" {
+			size++
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(strings.Repeat("#", size)))
+	}))
+	defer srv.Close()
+
+	data, err := fetchURL(srv.URL + "/limit")
+	if err != nil || len(data) != maxFetchSize {
+		t.Errorf("fetchURL() = %d bytes, %v, want %d bytes", len(data), err, maxFetchSize)
+	}
+
+	if _, err := fetchURL(srv.URL + "/large"); err == nil || !strings.Contains(err.Error(), "larger than 10 MiB") {
+		t.Errorf("fetchURL() error = %v, want a size error", err)
+	}
+}
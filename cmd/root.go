@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/michielvha/crossplane-docs/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +19,11 @@ var (
 	date    = "unknown"
 )
 
+var (
+	verbose bool
+	quiet   bool
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "crossplane-docs",
@@ -28,12 +38,83 @@ documentation tables with field names, types, descriptions, defaults, and valida
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	// Report generator option errors by the flags that set the options
+	for _, c := range rootCmd.Commands() {
+		if run := c.RunE; run != nil {
+			c.RunE = func(cmd *cobra.Command, args []string) error {
+				return flagError(run(cmd, args))
+			}
+		}
+	}
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// optionFlags maps the generator options to the flags that set them
+var optionFlags = map[string]string{
+	"Format":       "--format",
+	"Version":      "--api-version",
+	"TemplateFile": "--template",
+	"Audience":     "--audience",
+	"Only":         "--only",
+	"AllVersions":  "--all-versions",
+	"TableFormat":  "--output-format",
+}
+
+// flagError rewrites the message of a generator option error to name the flags
+// instead of the Options fields
+func flagError(err error) error {
+	var optErr *generator.OptionError
+	if !errors.As(err, &optErr) {
+		return err
+	}
+	return errors.New(strings.Replace(err.Error(), optErr.Error(), optErr.Message(func(option string) string {
+		if flag, ok := optionFlags[option]; ok {
+			return flag
+		}
+		return option
+	}), 1))
+}
+
 func init() {
-	// Global flags can be added here
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log per-file progress and timing to stderr")
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Only print generated output and errors")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+}
+
+// logWriter returns where progress messages go: stderr, so stdout only carries
+// generated documentation, or nowhere with --quiet
+func logWriter() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// infof logs a progress message unless --quiet is set
+func infof(format string, args ...interface{}) {
+	fmt.Fprintf(logWriter(), format+"\n", args...)
+}
+
+// warnf logs a problem that doesn't fail the command. Like errors, warnings
+// are shown even with --quiet.
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
+
+// debugf logs a detailed progress message when --verbose is set
+func debugf(format string, args ...interface{}) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// newOutput returns an output writer that logs its status messages like infof
+func newOutput() *output.Writer {
+	w := output.New()
+	w.Log = logWriter()
+	return w
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/michielvha/crossplane-docs/pkg/site"
+)
+
+// sitePageResult holds the outcome of generating the page for one file
+type sitePageResult struct {
+	page    *site.Page
+	skipped bool
+	err     error
+	elapsed time.Duration
+}
+
+// runSiteLayout generates a docs site tree organised by API group from a directory of XRDs,
+// generating up to jobs files concurrently. Files that fail are reported and left
+// out, unless strict is set.
+func runSiteLayout(dir, root string, jobs int, strict, emitMetadata bool, opts generator.Options, siteOpts site.Options) error {
+	files, err := findYAMLFiles(dir, true)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	gen := generator.New()
+	results := make([]sitePageResult, len(files))
+	forEachConcurrently(len(files), jobs, func(i int) {
+		results[i] = generateSitePage(gen, files[i], emitMetadata, opts)
+	})
+
+	// Report in file order so output is deterministic regardless of completion order
+	var pages []site.Page
+	var errs []error
+	for i, r := range results {
+		switch {
+		case r.err != nil:
+			errs = append(errs, r.err)
+		case r.skipped:
+			infof("Skipping %s: not an XRD or CRD", files[i])
+		default:
+			logPage(files[i], r)
+			pages = append(pages, *r.page)
+		}
+	}
+	if err := checkPageErrors(errs, len(pages), strict); err != nil {
+		return err
+	}
+
+	written, err := site.Write(root, pages, siteOpts)
+	if err != nil {
+		return err
+	}
+
+	infof("Documentation generated successfully: %d page(s), %d file(s) in %s%s", len(pages), len(written), root, failedSuffix(len(errs)))
+	return nil
+}
+
+// generateSitePage generates the site page for a single file
+func generateSitePage(gen *generator.Generator, file string, emitMetadata bool, opts generator.Options) sitePageResult {
+	start := time.Now()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return sitePageResult{err: fmt.Errorf("failed to read file %s: %w", file, err)}
+	}
+
+	xrd, err := generator.Parse(data)
+	if err != nil || !generator.IsDefinition(xrd.Kind) {
+		return sitePageResult{skipped: true}
+	}
+
+	markdown, err := gen.Generate(xrd, opts)
+	if err != nil {
+		return sitePageResult{err: fmt.Errorf("failed to generate documentation for %s: %w", file, err)}
+	}
+
+	page := &site.Page{
+		Group:   xrd.Spec.Group,
+		Kind:    xrd.Spec.Names.Kind,
+		Content: markdown,
+		Source:  file,
+	}
+	if v := xrd.DefaultVersion(); v != nil {
+		page.Description = v.Schema.OpenAPIV3Schema.Description
+	}
+	if emitMetadata {
+		page.Metadata = gen.Metadata(xrd, opts)
+	}
+	return sitePageResult{page: page, elapsed: time.Since(start)}
+}
+
+// checkPageErrors decides whether files that failed to generate fail the run:
+// always with strict or when no page was generated, and otherwise each failure
+// is reported and the other pages are still written
+func checkPageErrors(errs []error, generated int, strict bool) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if strict || generated == 0 {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		warnf("%v", err)
+	}
+	return nil
+}
+
+// failedSuffix notes the number of failed files in a summary, if any
+func failedSuffix(failed int) string {
+	if failed == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d file(s) failed)", failed)
+}
+
+// logPage logs the kind parsed from a file and how long its page took to generate
+func logPage(file string, r sitePageResult) {
+	debugf("%s: parsed %s.%s, generated in %s", file, r.page.Kind, r.page.Group, r.elapsed.Round(time.Millisecond))
+}
+
+// forEachConcurrently calls fn for every index in [0, n) using at most jobs goroutines
+func forEachConcurrently(n, jobs int, fn func(i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// findYAMLFiles returns the YAML files in dir in lexical order, including
+// those in subdirectories when recursive is set
+func findYAMLFiles(dir string, recursive bool) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && !recursive {
+			return fs.SkipDir
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
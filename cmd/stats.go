@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+var statsFormat string
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats [xrd-file]",
+	Short: "Print a summary of an XRD's API surface",
+	Long: `Print the number of spec, required and status fields, enums, the maximum
+nesting depth and the number of CEL rules of an XRD's documented version.
+
+Examples:
+  # Print the summary
+  crossplane-docs stats xrd.yaml
+
+  # Track API surface growth in CI
+  crossplane-docs stats xrd.yaml --format=json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Output format: text or json")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+
+	xrd, err := generator.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	stats, err := generator.New().Stats(xrd)
+	if err != nil {
+		return err
+	}
+
+	switch statsFormat {
+	case "json":
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode stats: %w", err)
+		}
+		fmt.Println(string(out))
+	case "text":
+		w := os.Stdout
+		fmt.Fprintf(w, "Kind:            %s (%s)\n", stats.Kind, stats.Version)
+		fmt.Fprintf(w, "Spec fields:     %d\n", stats.SpecFields)
+		fmt.Fprintf(w, "Required fields: %d\n", stats.RequiredFields)
+		fmt.Fprintf(w, "Status fields:   %d\n", stats.StatusFields)
+		fmt.Fprintf(w, "Enums:           %d\n", stats.Enums)
+		fmt.Fprintf(w, "Max depth:       %d\n", stats.MaxDepth)
+		fmt.Fprintf(w, "CEL rules:       %d\n", stats.CELRules)
+	default:
+		return fmt.Errorf("unknown format %q: use text or json", statsFormat)
+	}
+	return nil
+}
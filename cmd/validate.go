@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	requireConstraints bool
+	strict             bool
+	validateVersion    string
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [xrd-file]",
+	Short: "Validate an XRD file without generating documentation",
+	Long: `Validate a Crossplane XRD YAML file and report authoring problems.
+
+Checks:
+  - Duplicate keys within any mapping, including schema properties
+  - Structure: versions exist, at least one is served, version names are
+    unique and every version has a schema, with a spec property for XRDs
+  - With --require-constraints: scalar spec fields without any validation,
+    including those on list items and map values
+
+Warnings only fail validation with --strict.
+
+Examples:
+  # Validate an XRD
+  crossplane-docs validate xrd.yaml
+
+  # Fail on under-specified fields
+  crossplane-docs validate xrd.yaml --require-constraints --strict`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().BoolVar(&requireConstraints, "require-constraints", false, "Warn about scalar spec fields without required, enum, bounds or other validation")
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero when there are warnings")
+	validateCmd.Flags().StringVar(&validateVersion, "api-version", "", "Version checked by --require-constraints (default: the referenceable storage version)")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	xrdFile := args[0]
+
+	data, err := os.ReadFile(xrdFile)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if err := checkDuplicateKeys(xrdFile, data); err != nil {
+		return err
+	}
+
+	xrd, err := generator.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	if errs := generator.Validate(xrd); len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", xrdFile, e)
+		}
+		return fmt.Errorf("%s: %d error(s)", xrdFile, len(errs))
+	}
+
+	var warnings []string
+	if requireConstraints {
+		paths, err := generator.New().UnconstrainedFields(xrd, generator.Options{Version: validateVersion})
+		if err != nil {
+			return err
+		}
+		for _, fieldPath := range paths {
+			warnings = append(warnings, fmt.Sprintf("%s: field has no validation constraints", fieldPath))
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "%s: warning: %s\n", xrdFile, w)
+	}
+	if strict && len(warnings) > 0 {
+		return fmt.Errorf("%s: %d warning(s)", xrdFile, len(warnings))
+	}
+
+	fmt.Printf("%s: OK\n", xrdFile)
+	return nil
+}
+
+// checkDuplicateKeys reports duplicate keys in data to stderr and returns an error if any exist
+func checkDuplicateKeys(file string, data []byte) error {
+	dupes, err := generator.DetectDuplicateKeys(data)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range dupes {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, d.Error())
+	}
+	if len(dupes) > 0 {
+		return fmt.Errorf("%s: found %d duplicate key(s)", file, len(dupes))
+	}
+
+	return nil
+}
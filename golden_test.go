@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/docdiff"
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"github.com/michielvha/crossplane-docs/pkg/manifest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files that differ from the generated output")
+
+// TestGolden renders every example YAML file in testdata/golden and compares it
+// against the golden markdown next to it (example.yaml -> example.md), so output
+// changes are always intentional. Run with -update to rewrite the golden files.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no examples found in testdata/golden")
+	}
+
+	for _, input := range inputs {
+		input := input
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			got := renderGolden(t, input)
+			goldenFile := strings.TrimSuffix(input, ".yaml") + ".md"
+
+			if *update {
+				if err := os.WriteFile(goldenFile, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if string(want) != got {
+				t.Errorf("%s is out of date, rerun with -update if the change is intended:\n%s", goldenFile, docdiff.Lines(string(want), got))
+			}
+		})
+	}
+}
+
+// renderGolden generates the documentation for the XRDs and Compositions in an
+// example file using the CLI's default options
+func renderGolden(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	out, err := manifest.Generate(data, generator.Options{
+		ShowNested:     true,
+		QuantityFields: generator.DefaultQuantityFields,
+	}, composition.Options{
+		ShowPatches: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate documentation: %v", err)
+	}
+	return strings.TrimRight(out, "\n") + "\n"
+}
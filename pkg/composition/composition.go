@@ -1,19 +1,34 @@
 package composition
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
 	"text/template"
 
+	"github.com/michielvha/crossplane-docs/pkg/crd"
+	"github.com/michielvha/crossplane-docs/pkg/section"
 	"gopkg.in/yaml.v3"
 )
 
 // Options contains generation options
 type Options struct {
 	ShowPatches bool // show patch details
+	ShowBase    bool // show details taken from each resource base
+
+	GroupByProvider bool // split the managed resources table per provider
+	PreserveOrder   bool // list resources in declaration order instead of by name
+
+	ProviderDocs    bool   // link managed resource kinds to their provider docs
+	ProviderDocsURL string // URL template for the provider docs, with {group}, {version} and {kind}
+
+	ProviderCRDs *crd.Set // provider CRDs used to check patch targets, if any
+	XRD          *crd.Set // XRD of the composite, used to check patch sources, if any
+
+	Only      string // render only this section, e.g. resources or mappings
+	NoHeading bool   // drop the heading of the section rendered with Only
 }
 
 // Generator handles composition documentation generation
@@ -38,6 +53,39 @@ type CompositionSpec struct {
 	Mode             string           `yaml:"mode,omitempty"`
 	Resources        []Resource       `yaml:"resources,omitempty"`
 	Pipeline         []PipelineStep   `yaml:"pipeline,omitempty"`
+	Environment      *Environment     `yaml:"environment,omitempty"`
+}
+
+// Environment contains the composition environment configuration
+type Environment struct {
+	EnvironmentConfigs []EnvironmentSource `yaml:"environmentConfigs,omitempty"`
+	Patches            []Patch             `yaml:"patches,omitempty"` // Between the XR and the environment
+}
+
+// EnvironmentSource references or selects an EnvironmentConfig
+type EnvironmentSource struct {
+	Type     string               `yaml:"type,omitempty"`
+	Ref      *EnvironmentRef      `yaml:"ref,omitempty"`
+	Selector *EnvironmentSelector `yaml:"selector,omitempty"`
+}
+
+// EnvironmentRef references an EnvironmentConfig by name
+type EnvironmentRef struct {
+	Name string `yaml:"name"`
+}
+
+// EnvironmentSelector selects EnvironmentConfigs by labels
+type EnvironmentSelector struct {
+	Mode        string          `yaml:"mode,omitempty"`
+	MatchLabels []SelectorLabel `yaml:"matchLabels,omitempty"`
+}
+
+// SelectorLabel represents a label used to select EnvironmentConfigs
+type SelectorLabel struct {
+	Key                string `yaml:"key"`
+	Type               string `yaml:"type,omitempty"`
+	ValueFromFieldPath string `yaml:"valueFromFieldPath,omitempty"`
+	Value              string `yaml:"value,omitempty"`
 }
 
 // CompositeTypeRef references the XR type
@@ -52,6 +100,22 @@ type Resource struct {
 	Base              map[string]interface{} `yaml:"base"`
 	Patches           []Patch                `yaml:"patches,omitempty"`
 	ConnectionDetails []ConnectionDetail     `yaml:"connectionDetails,omitempty"`
+	ReadinessChecks   []ReadinessCheck       `yaml:"readinessChecks,omitempty"`
+}
+
+// ReadinessCheck represents a check used to determine whether a resource is ready
+type ReadinessCheck struct {
+	Type           string          `yaml:"type"`
+	FieldPath      string          `yaml:"fieldPath,omitempty"`
+	MatchString    string          `yaml:"matchString,omitempty"`
+	MatchInteger   *int64          `yaml:"matchInteger,omitempty"`
+	MatchCondition *MatchCondition `yaml:"matchCondition,omitempty"`
+}
+
+// MatchCondition represents a condition a readiness check matches
+type MatchCondition struct {
+	Type   string `yaml:"type"`
+	Status string `yaml:"status"`
 }
 
 // PipelineStep represents a function in the pipeline
@@ -59,6 +123,20 @@ type PipelineStep struct {
 	Step        string                 `yaml:"step"`
 	FunctionRef FunctionRef            `yaml:"functionRef"`
 	Input       map[string]interface{} `yaml:"input,omitempty"`
+	Credentials []FunctionCredentials  `yaml:"credentials,omitempty"`
+}
+
+// FunctionCredentials represents credentials supplied to a composition function
+type FunctionCredentials struct {
+	Name      string     `yaml:"name"`
+	Source    string     `yaml:"source"`
+	SecretRef *SecretRef `yaml:"secretRef,omitempty"`
+}
+
+// SecretRef references a secret
+type SecretRef struct {
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
 }
 
 // FunctionRef references a composition function
@@ -72,6 +150,7 @@ type Patch struct {
 	FromFieldPath string                 `yaml:"fromFieldPath,omitempty"`
 	ToFieldPath   string                 `yaml:"toFieldPath,omitempty"`
 	Combine       *Combine               `yaml:"combine,omitempty"`
+	Transforms    []interface{}          `yaml:"transforms,omitempty"`
 	Policy        map[string]interface{} `yaml:"policy,omitempty"`
 }
 
@@ -80,6 +159,9 @@ type Combine struct {
 	Variables []Variable `yaml:"variables"`
 	Strategy  string     `yaml:"strategy"`
 	String    *StringFmt `yaml:"string,omitempty"`
+
+	// Settings of other strategies, keyed by strategy name
+	Settings map[string]interface{} `yaml:",inline"`
 }
 
 // Variable represents a combine variable
@@ -98,41 +180,112 @@ type ConnectionDetail struct {
 	Type                    string `yaml:"type,omitempty"`
 	FromConnectionSecretKey string `yaml:"fromConnectionSecretKey,omitempty"`
 	FromFieldPath           string `yaml:"fromFieldPath,omitempty"`
+	Value                   string `yaml:"value,omitempty"`
 }
 
 // ManagedResource represents a documented managed resource
 type ManagedResource struct {
-	Name        string
-	Kind        string
-	APIVersion  string
-	Description string
-	Patches     []PatchInfo
+	Name               string
+	Kind               string
+	APIVersion         string
+	Description        string
+	ManagementPolicies []string
+	ProviderConfig     string
+	ClusterName        string
+	Labels             []string
+	ReadinessChecks    []string
+	ConnectionDetails  []ConnectionDetailInfo
+	Patches            []PatchInfo
+	UnsetRequired      []string // Fields the provider CRD requires that are neither in the base nor patched
+}
+
+// ConnectionDetailInfo represents a documented connection detail
+type ConnectionDetailInfo struct {
+	Name       string
+	SourceType string
+	Source     string
 }
 
 // PatchInfo represents patch information
 type PatchInfo struct {
 	XRDField       string
 	MappedTo       string
+	TargetNote     string // Explains well-known metadata targets, e.g. "sets external name"
+	Policy         string // Explains the patch policy, e.g. "required source"
 	Transformation string
+	ProviderField  string   // Whether the provider CRD requires the target, when the CRD is known
+	UnknownFields  []string // XR paths read by the patch that aren't in the XRD, when the XRD is known
+
+	xrSources []string // XR field paths the patch reads
+}
+
+// ResourceGroup represents managed resources sharing a provider
+type ResourceGroup struct {
+	Provider  string // Empty when resources aren't grouped
+	Resources []ManagedResource
+}
+
+// EnvironmentConfigInfo represents a documented EnvironmentConfig source
+type EnvironmentConfigInfo struct {
+	Type   string
+	Config string
+	Labels string
+}
+
+// EnvironmentInput represents an environment key the composition reads
+type EnvironmentInput struct {
+	Key    string // Field path in the environment
+	ReadBy string // Resource whose patch reads the key, or the composite for environment patches
+	Target string // Field path the value is patched to
 }
 
 // GenerateFromFile generates documentation from a composition file
 func (g *Generator) GenerateFromFile(filename string, opts Options) (string, error) {
-	data, err := os.ReadFile(filename)
+	f, err := os.Open(filename)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
+	defer f.Close()
 
+	return g.GenerateFromReader(f, opts)
+}
+
+// GenerateFromReader generates documentation from composition YAML read from r
+func (g *Generator) GenerateFromReader(r io.Reader, opts Options) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	comp, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	return g.Generate(comp, opts)
+}
+
+// Parse parses Composition YAML
+func Parse(data []byte) (*Composition, error) {
 	var comp Composition
 	if err := yaml.Unmarshal(data, &comp); err != nil {
-		return "", fmt.Errorf("failed to parse Composition YAML: %w", err)
+		return nil, fmt.Errorf("failed to parse Composition YAML: %w", err)
 	}
-
-	return g.Generate(&comp, opts)
+	return &comp, nil
 }
 
 // Generate generates documentation from a Composition struct
 func (g *Generator) Generate(comp *Composition, opts Options) (string, error) {
+	var b strings.Builder
+	if err := g.GenerateTo(&b, comp, opts); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// GenerateTo generates documentation from a Composition struct, executing the
+// template directly against w, which may receive partial output when rendering fails
+func (g *Generator) GenerateTo(w io.Writer, comp *Composition, opts Options) error {
 	// Extract resources
 	var resources []ManagedResource
 
@@ -144,7 +297,9 @@ func (g *Generator) Generate(comp *Composition, opts Options) (string, error) {
 		resources = g.extractResources(comp.Spec.Resources, opts)
 	}
 
-	return g.generateMarkdown(comp, resources, opts)
+	g.checkXRDFields(comp, resources, opts)
+
+	return g.writeMarkdown(w, comp, resources, opts)
 }
 
 // extractPipelineResources extracts resources from pipeline mode
@@ -174,10 +329,25 @@ func (g *Generator) extractResources(resources []Resource, opts Options) []Manag
 			Name:       res.Name,
 			Kind:       getStringFromMap(res.Base, "kind"),
 			APIVersion: getStringFromMap(res.Base, "apiVersion"),
+
+			ManagementPolicies: getStringSliceFromMap(res.Base, "spec.managementPolicies"),
+		}
+
+		for _, cd := range res.ConnectionDetails {
+			mr.ConnectionDetails = append(mr.ConnectionDetails, describeConnectionDetail(cd))
+		}
+
+		for _, rc := range res.ReadinessChecks {
+			mr.ReadinessChecks = append(mr.ReadinessChecks, formatReadinessCheck(rc))
 		}
 
+		patches := g.extractPatches(res.Patches)
+		g.checkProviderSchema(&mr, res.Base, patches, opts)
 		if opts.ShowPatches {
-			mr.Patches = g.extractPatches(res.Patches)
+			mr.Patches = patches
+		}
+		if opts.ShowBase {
+			g.describeBase(&mr, res.Base, patches)
 		}
 
 		result = append(result, mr)
@@ -192,20 +362,229 @@ func (g *Generator) parseResource(resMap map[string]interface{}, opts Options) M
 		Name: getString(resMap, "name"),
 	}
 
-	if base, ok := resMap["base"].(map[string]interface{}); ok {
+	base, _ := resMap["base"].(map[string]interface{})
+	if base != nil {
 		resource.Kind = getString(base, "kind")
 		resource.APIVersion = getString(base, "apiVersion")
+		resource.ManagementPolicies = getStringSliceFromMap(base, "spec.managementPolicies")
 	}
 
-	if opts.ShowPatches {
-		if patches, ok := resMap["patches"].([]interface{}); ok {
-			resource.Patches = g.parsePatchesFromInterface(patches)
+	if details, ok := resMap["connectionDetails"].([]interface{}); ok {
+		for _, d := range details {
+			if detailMap, ok := d.(map[string]interface{}); ok {
+				resource.ConnectionDetails = append(resource.ConnectionDetails, describeConnectionDetail(ConnectionDetail{
+					Name:                    getString(detailMap, "name"),
+					Type:                    getString(detailMap, "type"),
+					FromConnectionSecretKey: getString(detailMap, "fromConnectionSecretKey"),
+					FromFieldPath:           getString(detailMap, "fromFieldPath"),
+					Value:                   scalarString(detailMap, "value"),
+				}))
+			}
 		}
 	}
 
+	if checks, ok := resMap["readinessChecks"].([]interface{}); ok {
+		for _, c := range checks {
+			if checkMap, ok := c.(map[string]interface{}); ok {
+				resource.ReadinessChecks = append(resource.ReadinessChecks, formatReadinessCheck(parseReadinessCheck(checkMap)))
+			}
+		}
+	}
+
+	var patches []PatchInfo
+	if rawPatches, ok := resMap["patches"].([]interface{}); ok {
+		patches = g.parsePatchesFromInterface(rawPatches)
+	}
+	g.checkProviderSchema(&resource, base, patches, opts)
+	if opts.ShowPatches {
+		resource.Patches = patches
+	}
+	if opts.ShowBase {
+		g.describeBase(&resource, base, patches)
+	}
+
 	return resource
 }
 
+// describeBase fills in the resource details taken from its base, noting values
+// that are patched from the XR rather than set statically
+func (g *Generator) describeBase(resource *ManagedResource, base map[string]interface{}, patches []PatchInfo) {
+	resource.ProviderConfig = describeBaseValue(getStringFromMap(base, "spec.providerConfigRef.name"), "spec.providerConfigRef.name", patches)
+	resource.Labels = describeLabels(getMapFromMap(base, "metadata.labels"), patches)
+	resource.ClusterName = describeClusterName(base, patches)
+}
+
+// describeClusterName describes the name a composed resource gets in the cluster
+func describeClusterName(base map[string]interface{}, patches []PatchInfo) string {
+	for _, p := range patches {
+		if p.MappedTo == "metadata.name" {
+			if p.XRDField != "" {
+				return fmt.Sprintf("patched from `%s`", p.XRDField)
+			}
+			return "patched from the XR"
+		}
+	}
+	if name := getStringFromMap(base, "metadata.name"); name != "" {
+		return fmt.Sprintf("`%s`", name)
+	}
+	if prefix := getStringFromMap(base, "metadata.generateName"); prefix != "" {
+		return fmt.Sprintf("`%s<random>`", prefix)
+	}
+	return "derived from XR (`<xr-name>-<random>`)"
+}
+
+// describeLabels lists the labels applied to a resource: static labels from the
+// base and labels patched from the XR
+func describeLabels(static map[string]interface{}, patches []PatchInfo) []string {
+	var result []string
+
+	patched := make(map[string]string)
+	for _, p := range patches {
+		key, ok := labelKey(p.MappedTo)
+		if !ok {
+			continue
+		}
+		source := "patched from the XR"
+		if p.XRDField != "" {
+			source = fmt.Sprintf("patched from `%s`", p.XRDField)
+		}
+		patched[key] = source
+	}
+
+	keys := make([]string, 0, len(static))
+	for k := range static {
+		if _, ok := patched[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, fmt.Sprintf("`%s: %v`", k, static[k]))
+	}
+
+	patchedKeys := make([]string, 0, len(patched))
+	for k := range patched {
+		patchedKeys = append(patchedKeys, k)
+	}
+	sort.Strings(patchedKeys)
+	for _, k := range patchedKeys {
+		result = append(result, fmt.Sprintf("`%s` (%s)", k, patched[k]))
+	}
+
+	return result
+}
+
+// describeTarget explains patch targets that configure resource identity or metadata
+func describeTarget(toFieldPath string) string {
+	switch toFieldPath {
+	case "metadata.name":
+		return "sets resource name"
+	case "metadata.namespace":
+		return "sets namespace"
+	}
+	if key, ok := mapKey(toFieldPath, "metadata.annotations"); ok {
+		if key == "crossplane.io/external-name" {
+			return "sets external name"
+		}
+		return fmt.Sprintf("sets annotation `%s`", key)
+	}
+	if key, ok := labelKey(toFieldPath); ok {
+		return fmt.Sprintf("sets label `%s`", key)
+	}
+	return ""
+}
+
+// mergePolicies explain the toFieldPath policies that merge into the target
+// instead of replacing it
+var mergePolicies = map[string]string{
+	"MergeObjects":                  "merges objects",
+	"MergeObjectsAppendArrays":      "merges objects, appends arrays",
+	"ForceMergeObjects":             "merges objects, overwrites keys",
+	"ForceMergeObjectsAppendArrays": "merges objects, overwrites keys, appends arrays",
+}
+
+// describePolicy explains a patch policy: a required source, which fails the
+// patch when the field is unset, and how the value is merged into the target.
+// Missing or partial policies describe only what they set.
+func describePolicy(policy map[string]interface{}) string {
+	var notes []string
+	if getString(policy, "fromFieldPath") == "Required" {
+		notes = append(notes, "required source")
+	}
+
+	switch to := getString(policy, "toFieldPath"); {
+	case to == "" || to == "Replace":
+	case mergePolicies[to] != "":
+		notes = append(notes, mergePolicies[to])
+	default:
+		notes = append(notes, fmt.Sprintf("`%s`", to))
+	}
+
+	// The deprecated mergeOptions predate the toFieldPath policy
+	if merge, ok := policy["mergeOptions"].(map[string]interface{}); ok {
+		if keep, _ := merge["keepMapValues"].(bool); keep {
+			notes = append(notes, "keeps existing map values")
+		}
+		if appendSlice, _ := merge["appendSlice"].(bool); appendSlice {
+			notes = append(notes, "appends arrays")
+		}
+	}
+
+	return strings.Join(notes, ", ")
+}
+
+// labelKey returns the label key set by a metadata.labels field path, supporting
+// both metadata.labels[key] and metadata.labels.key
+func labelKey(fieldPath string) (string, bool) {
+	return mapKey(fieldPath, "metadata.labels")
+}
+
+// mapKey returns the key a field path addresses within the map at prefix, supporting
+// both prefix[key] (including quoted keys) and prefix.key
+func mapKey(fieldPath, prefix string) (string, bool) {
+	segments, err := parseFieldPath(fieldPath)
+	if err != nil {
+		return "", false
+	}
+	prefixSegments, _ := parseFieldPath(prefix)
+	if len(segments) <= len(prefixSegments) {
+		return "", false
+	}
+	for i, seg := range prefixSegments {
+		if segments[i] != seg {
+			return "", false
+		}
+	}
+
+	// A bracketed key is taken whole; dotted keys such as labels.app.kubernetes.io/name
+	// are joined back, as label keys commonly contain dots
+	rest := segments[len(prefixSegments):]
+	if rest[0].bracketed {
+		return rest[0].key, len(rest) == 1
+	}
+	keys := make([]string, len(rest))
+	for i, seg := range rest {
+		if seg.isIndex || seg.bracketed {
+			return "", false
+		}
+		keys[i] = seg.key
+	}
+	return strings.Join(keys, "."), true
+}
+
+// describeBaseValue describes the value a resource ends up with at toFieldPath
+func describeBaseValue(static, toFieldPath string, patches []PatchInfo) string {
+	for _, p := range patches {
+		if p.MappedTo == toFieldPath && p.XRDField != "" {
+			return fmt.Sprintf("patched from `%s`", p.XRDField)
+		}
+	}
+	if static != "" {
+		return fmt.Sprintf("`%s`", static)
+	}
+	return "-"
+}
+
 // extractPatches extracts patch information
 func (g *Generator) extractPatches(patches []Patch) []PatchInfo {
 	var result []PatchInfo
@@ -214,8 +593,14 @@ func (g *Generator) extractPatches(patches []Patch) []PatchInfo {
 		info := PatchInfo{
 			XRDField:       p.FromFieldPath,
 			MappedTo:       p.ToFieldPath,
+			TargetNote:     describeTarget(p.ToFieldPath),
+			Policy:         describePolicy(p.Policy),
 			Transformation: g.formatTransformation(p),
 		}
+		if p.Combine != nil {
+			info.XRDField = strings.Join(p.Combine.sources(), ", ")
+		}
+		info.xrSources = xrSources(p.Type, p.FromFieldPath, p.Combine)
 		result = append(result, info)
 	}
 
@@ -232,17 +617,23 @@ func (g *Generator) parsePatchesFromInterface(patches []interface{}) []PatchInfo
 				XRDField: getString(patchMap, "fromFieldPath"),
 				MappedTo: getString(patchMap, "toFieldPath"),
 			}
+			info.TargetNote = describeTarget(info.MappedTo)
+			policy, _ := patchMap["policy"].(map[string]interface{})
+			info.Policy = describePolicy(policy)
 
 			// Handle combine transformations
-			if combine, ok := patchMap["combine"].(map[string]interface{}); ok {
-				if str, ok := combine["string"].(map[string]interface{}); ok {
-					if fmt, ok := str["fmt"].(string); ok {
-						info.Transformation = fmt
-					}
-				}
-			} else if info.XRDField != "" {
-				info.Transformation = "Direct copy"
+			var combine *Combine
+			if m, ok := patchMap["combine"].(map[string]interface{}); ok {
+				combine = combineFromMap(m)
+				info.XRDField = strings.Join(combine.sources(), ", ")
 			}
+			transforms, _ := patchMap["transforms"].([]interface{})
+			info.Transformation = g.formatTransformation(Patch{
+				Type:       getString(patchMap, "type"),
+				Combine:    combine,
+				Transforms: transforms,
+			})
+			info.xrSources = xrSources(getString(patchMap, "type"), info.XRDField, combine)
 
 			if info.XRDField != "" || info.MappedTo != "" {
 				result = append(result, info)
@@ -253,59 +644,361 @@ func (g *Generator) parsePatchesFromInterface(patches []interface{}) []PatchInfo
 	return result
 }
 
+// extractEnvironmentConfigs extracts the referenced and selected EnvironmentConfigs
+func (g *Generator) extractEnvironmentConfigs(comp *Composition) []EnvironmentConfigInfo {
+	var result []EnvironmentConfigInfo
+
+	if comp.Spec.Environment == nil {
+		return result
+	}
+
+	for _, src := range comp.Spec.Environment.EnvironmentConfigs {
+		info := EnvironmentConfigInfo{
+			Type:   src.Type,
+			Config: "-",
+			Labels: "-",
+		}
+
+		if src.Ref != nil && src.Ref.Name != "" {
+			info.Config = src.Ref.Name
+		}
+
+		if src.Selector != nil {
+			if src.Selector.Mode != "" {
+				info.Config = fmt.Sprintf("mode: %s", src.Selector.Mode)
+			}
+
+			var labels []string
+			for _, l := range src.Selector.MatchLabels {
+				labels = append(labels, g.formatSelectorLabel(l))
+			}
+			if len(labels) > 0 {
+				info.Labels = strings.Join(labels, ", ")
+			}
+		}
+
+		// Type defaults to Reference when omitted
+		if info.Type == "" {
+			info.Type = "Reference"
+		}
+
+		result = append(result, info)
+	}
+
+	return result
+}
+
+// formatSelectorLabel formats an EnvironmentConfig selector label
+func (g *Generator) formatSelectorLabel(l SelectorLabel) string {
+	if l.Type == "Value" || (l.Type == "" && l.ValueFromFieldPath == "") {
+		return fmt.Sprintf("`%s` = `%s`", l.Key, l.Value)
+	}
+	return fmt.Sprintf("`%s` from %s", l.Key, l.ValueFromFieldPath)
+}
+
+// groupResources partitions resources per provider when requested, keeping
+// resources whose provider can't be classified under "Other"
+func (g *Generator) groupResources(resources []ManagedResource, opts Options) []ResourceGroup {
+	if !opts.GroupByProvider {
+		return []ResourceGroup{{Resources: resources}}
+	}
+
+	byProvider := make(map[string][]ManagedResource)
+	var providers []string
+	for _, r := range resources {
+		provider := providerFromAPIVersion(r.APIVersion)
+		if _, ok := byProvider[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		byProvider[provider] = append(byProvider[provider], r)
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		if (providers[i] == "Other") != (providers[j] == "Other") {
+			return providers[j] == "Other"
+		}
+		return providers[i] < providers[j]
+	})
+
+	groups := make([]ResourceGroup, 0, len(providers))
+	for _, p := range providers {
+		groups = append(groups, ResourceGroup{Provider: p, Resources: byProvider[p]})
+	}
+	return groups
+}
+
+// providerFromAPIVersion derives the provider from an apiVersion group, e.g.
+// rds.aws.upbound.io/v1beta1 and the namespaced rds.aws.m.upbound.io/v1beta1
+// both belong to aws.upbound.io
+func providerFromAPIVersion(apiVersion string) string {
+	group, _, found := strings.Cut(apiVersion, "/")
+	if !found || !strings.Contains(group, ".") {
+		return "Other"
+	}
+
+	labels := strings.Split(clusterGroup(group), ".")
+	if len(labels) > 3 {
+		labels = labels[len(labels)-3:]
+	}
+	return strings.Join(labels, ".")
+}
+
+// clusterGroup returns the API group of the cluster-scoped managed resources a
+// group belongs to, dropping the .m. infix of namespaced Crossplane v2 managed
+// resources, e.g. ec2.aws.m.upbound.io becomes ec2.aws.upbound.io
+func clusterGroup(group string) string {
+	labels := strings.Split(group, ".")
+	for i := 1; i < len(labels)-2; i++ {
+		if labels[i] == "m" {
+			return strings.Join(append(labels[:i:i], labels[i+1:]...), ".")
+		}
+	}
+	return group
+}
+
+// describeConnectionDetail describes where a connection detail gets its value,
+// inferring the type from the populated source when it's omitted
+func describeConnectionDetail(cd ConnectionDetail) ConnectionDetailInfo {
+	info := ConnectionDetailInfo{Name: cd.Name, SourceType: cd.Type}
+
+	if info.SourceType == "" {
+		switch {
+		case cd.FromConnectionSecretKey != "":
+			info.SourceType = "FromConnectionSecretKey"
+		case cd.FromFieldPath != "":
+			info.SourceType = "FromFieldPath"
+		case cd.Value != "":
+			info.SourceType = "FromValue"
+		}
+	}
+
+	switch info.SourceType {
+	case "FromConnectionSecretKey":
+		info.Source = fmt.Sprintf("secret key `%s`", cd.FromConnectionSecretKey)
+		if info.Name == "" {
+			info.Name = cd.FromConnectionSecretKey
+		}
+	case "FromFieldPath":
+		info.Source = fmt.Sprintf("field `%s`", cd.FromFieldPath)
+	case "FromValue":
+		info.Source = fmt.Sprintf("value `%s`", cd.Value)
+	default:
+		info.Source = "-"
+	}
+
+	return info
+}
+
+// parseReadinessCheck parses a readiness check from a map
+func parseReadinessCheck(m map[string]interface{}) ReadinessCheck {
+	rc := ReadinessCheck{
+		Type:        getString(m, "type"),
+		FieldPath:   getString(m, "fieldPath"),
+		MatchString: getString(m, "matchString"),
+	}
+	if v, ok := m["matchInteger"].(int); ok {
+		n := int64(v)
+		rc.MatchInteger = &n
+	}
+	if mc, ok := m["matchCondition"].(map[string]interface{}); ok {
+		rc.MatchCondition = &MatchCondition{
+			Type:   getString(mc, "type"),
+			Status: getString(mc, "status"),
+		}
+	}
+	return rc
+}
+
+// formatReadinessCheck describes a readiness check
+func formatReadinessCheck(rc ReadinessCheck) string {
+	switch rc.Type {
+	case "MatchString":
+		return fmt.Sprintf("`%s` is `%s`", rc.FieldPath, rc.MatchString)
+	case "MatchInteger":
+		if rc.MatchInteger != nil {
+			return fmt.Sprintf("`%s` is `%d`", rc.FieldPath, *rc.MatchInteger)
+		}
+	case "MatchTrue":
+		return fmt.Sprintf("`%s` is true", rc.FieldPath)
+	case "MatchFalse":
+		return fmt.Sprintf("`%s` is false", rc.FieldPath)
+	case "NonEmpty":
+		return fmt.Sprintf("`%s` is set", rc.FieldPath)
+	case "MatchCondition":
+		if rc.MatchCondition != nil {
+			return fmt.Sprintf("condition `%s` is `%s`", rc.MatchCondition.Type, rc.MatchCondition.Status)
+		}
+	case "None":
+		return "always ready"
+	}
+	return rc.Type
+}
+
+// autoReadyStep returns the pipeline step running function-auto-ready, if any
+func autoReadyStep(comp *Composition) *PipelineStep {
+	for i, step := range comp.Spec.Pipeline {
+		if strings.HasSuffix(step.FunctionRef.Name, "function-auto-ready") {
+			return &comp.Spec.Pipeline[i]
+		}
+	}
+	return nil
+}
+
+// formatCredentials describes the credentials supplied to a pipeline step
+func formatCredentials(creds []FunctionCredentials) string {
+	parts := make([]string, 0, len(creds))
+	for _, c := range creds {
+		part := fmt.Sprintf("`%s` (%s", c.Name, c.Source)
+		if c.SecretRef != nil {
+			part += fmt.Sprintf(" `%s/%s`", c.SecretRef.Namespace, c.SecretRef.Name)
+		}
+		parts = append(parts, part+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatManagementPolicies formats management policies, flagging resources
+// the controller doesn't have full authority over
+func formatManagementPolicies(policies []string) string {
+	if len(policies) == 0 {
+		return "-"
+	}
+
+	quoted := make([]string, len(policies))
+	for i, p := range policies {
+		quoted[i] = fmt.Sprintf("`%s`", p)
+	}
+	result := strings.Join(quoted, ", ")
+
+	switch {
+	case len(policies) == 1 && policies[0] == "Observe":
+		result += " ⚠️ observe-only, never created or modified"
+	case !fullControl(policies):
+		result += " ⚠️ partial control"
+	}
+
+	return result
+}
+
+// fullControlPolicies are the management policies that together amount to "*"
+var fullControlPolicies = []string{"Observe", "Create", "Update", "Delete", "LateInitialize"}
+
+// fullControl reports whether the policies grant every action, either through
+// "*" or by listing each of them
+func fullControl(policies []string) bool {
+	if contains(policies, "*") {
+		return true
+	}
+	for _, p := range fullControlPolicies {
+		if !contains(policies, p) {
+			return false
+		}
+	}
+	return true
+}
+
 // formatTransformation formats the transformation description
 func (g *Generator) formatTransformation(p Patch) string {
-	if p.Combine != nil && p.Combine.String != nil {
-		return p.Combine.String.Fmt
+	var base string
+	switch {
+	case p.Combine != nil:
+		base = p.Combine.describe()
+	case p.Type == "" || p.Type == "FromCompositeFieldPath" || p.Type == "ToCompositeFieldPath":
+		base = "Direct copy"
+	default:
+		base = p.Type
 	}
-	if p.Type == "FromCompositeFieldPath" || p.Type == "ToCompositeFieldPath" {
-		return "Direct copy"
+
+	transforms := describeTransforms(p.Transforms)
+	switch {
+	case transforms == "":
+		return base
+	case base == "Direct copy":
+		return transforms
+	default:
+		return base + " → " + transforms
 	}
-	return p.Type
 }
 
-// generateMarkdown generates the final markdown output
-func (g *Generator) generateMarkdown(comp *Composition, resources []ManagedResource, opts Options) (string, error) {
-	// Sort resources by name
-	sort.Slice(resources, func(i, j int) bool {
-		return resources[i].Name < resources[j].Name
-	})
+// combineFromMap builds a Combine from a patch parsed as a generic map
+func combineFromMap(m map[string]interface{}) *Combine {
+	combine := &Combine{
+		Strategy: getString(m, "strategy"),
+		Settings: make(map[string]interface{}),
+	}
+
+	if vars, ok := m["variables"].([]interface{}); ok {
+		for _, v := range vars {
+			if varMap, ok := v.(map[string]interface{}); ok {
+				combine.Variables = append(combine.Variables, Variable{FromFieldPath: getString(varMap, "fromFieldPath")})
+			}
+		}
+	}
+	if str, ok := m["string"].(map[string]interface{}); ok {
+		combine.String = &StringFmt{Fmt: getString(str, "fmt")}
+	}
+	for k, v := range m {
+		if k != "variables" && k != "strategy" && k != "string" {
+			combine.Settings[k] = v
+		}
+	}
+
+	return combine
+}
+
+// sources returns the field paths of the combined variables
+func (c *Combine) sources() []string {
+	paths := make([]string, 0, len(c.Variables))
+	for _, v := range c.Variables {
+		paths = append(paths, v.FromFieldPath)
+	}
+	return paths
+}
+
+// describe describes how the variables are combined: the format of a string
+// combine, or the strategy and its settings for any other strategy
+func (c *Combine) describe() string {
+	strategy := c.Strategy
+	if strategy == "" && c.String != nil {
+		strategy = "string"
+	}
+
+	if strategy == "string" && c.String != nil {
+		return "Combine (string): " + section.CodeTableCell(c.String.Fmt)
+	}
+
+	desc := fmt.Sprintf("Combine (%s) of %d variables", strategy, len(c.Variables))
+	if strategy == "" {
+		desc = fmt.Sprintf("Combine of %d variables", len(c.Variables))
+	}
+	if settings, ok := c.Settings[strategy]; ok {
+		desc += ": " + section.CodeTableCell(formatValue(settings))
+	}
+	return desc
+}
+
+// writeMarkdown writes the final markdown output
+func (g *Generator) writeMarkdown(w io.Writer, comp *Composition, resources []ManagedResource, opts Options) error {
+	// Sort resources by name unless the declared order is kept
+	if !opts.PreserveOrder {
+		sort.SliceStable(resources, func(i, j int) bool {
+			return resources[i].Name < resources[j].Name
+		})
+	}
 
-	tmpl := `# {{ .Composition.Spec.CompositeTypeRef.Kind }} Composition
-
-**Composition Name:** {{ .Name }}  
-**Composite Type:** {{ .Composition.Spec.CompositeTypeRef.APIVersion }}/{{ .Composition.Spec.CompositeTypeRef.Kind }}  
-{{ if .Composition.Spec.Mode }}**Mode:** {{ .Composition.Spec.Mode }}{{ end }}
-
-## Managed Resources
-
-This composition creates {{ len .Resources }} managed resource(s):
-
-| Resource Name | Kind | API Version |
-|---------------|------|-------------|
-{{ range .Resources -}}
-| {{ .Name }} | {{ .Kind }} | {{ .APIVersion }} |
-{{ end }}
-{{ if .ShowPatches }}
-## Field Mappings
-{{ range .Resources }}
-### {{ .Name }} ({{ .Kind }})
-{{ if .Patches }}
-| XRD Field | Mapped To | Transformation |
-|-----------|-----------|----------------|
-{{ range .Patches -}}
-| {{ if .XRDField }}{{ .XRDField }}{{ else }}-{{ end }} | {{ .MappedTo }} | {{ .Transformation }} |
-{{ end }}
-{{ else }}
-No patches defined.
-{{ end }}
-{{ end }}
-{{ end }}
-`
-
-	t, err := template.New("markdown").Parse(tmpl)
+	funcMap := template.FuncMap{
+		"managementPolicies": formatManagementPolicies,
+		"join":               strings.Join,
+		"cell":               section.EscapeTableCell,
+		"credentials":        formatCredentials,
+		"stepResources":      stepResources,
+		"inc":                func(i int) int { return i + 1 },
+		"kindLink":           kindLinker(opts),
+	}
+
+	t, err := template.New("markdown").Funcs(funcMap).Parse(compositionTemplate)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	name := "unknown"
@@ -313,24 +1006,93 @@ No patches defined.
 		name = metadata
 	}
 
+	hasManagementPolicies := false
+	hasReadinessChecks := false
+	hasConnectionDetails := false
+	for _, r := range resources {
+		hasConnectionDetails = hasConnectionDetails || len(r.ConnectionDetails) > 0
+		hasManagementPolicies = hasManagementPolicies || len(r.ManagementPolicies) > 0
+		hasReadinessChecks = hasReadinessChecks || len(r.ReadinessChecks) > 0
+	}
+
+	var credentialSteps []PipelineStep
+	for _, step := range comp.Spec.Pipeline {
+		if len(step.Credentials) > 0 {
+			credentialSteps = append(credentialSteps, step)
+		}
+	}
+
 	data := struct {
-		Composition *Composition
-		Name        string
-		Resources   []ManagedResource
-		ShowPatches bool
+		Composition        *Composition
+		Name               string
+		Resources          []ManagedResource
+		ResourceGroups     []ResourceGroup
+		EnvironmentConfigs []EnvironmentConfigInfo
+		EnvironmentInputs  []EnvironmentInput
+		ShowPatches        bool
+		ShowBase           bool
+		PreserveOrder      bool
+		ProviderCRDs       bool
+
+		HasManagementPolicies bool
+		HasReadinessChecks    bool
+		HasConnectionDetails  bool
+		AutoReady             *PipelineStep
+		CredentialSteps       []PipelineStep
 	}{
-		Composition: comp,
-		Name:        name,
-		Resources:   resources,
-		ShowPatches: opts.ShowPatches,
+		Composition:        comp,
+		Name:               name,
+		Resources:          resources,
+		ResourceGroups:     g.groupResources(resources, opts),
+		EnvironmentConfigs: g.extractEnvironmentConfigs(comp),
+		EnvironmentInputs:  g.environmentInputs(comp),
+		ShowPatches:        opts.ShowPatches,
+		ShowBase:           opts.ShowBase,
+		PreserveOrder:      opts.PreserveOrder,
+		ProviderCRDs:       opts.ProviderCRDs != nil,
+
+		HasManagementPolicies: hasManagementPolicies,
+		HasReadinessChecks:    hasReadinessChecks,
+		HasConnectionDetails:  hasConnectionDetails,
+		AutoReady:             autoReadyStep(comp),
+		CredentialSteps:       credentialSteps,
 	}
 
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
-		return "", err
+	if opts.Only == "" {
+		return section.Write(w, t, compositionSections, data)
+	}
+
+	doc, err := section.Render(t, compositionSections, opts.Only, !opts.NoHeading, data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, doc)
+	return err
+}
+
+// stepResources returns the number of resources a pipeline step's input composes
+func stepResources(step PipelineStep) int {
+	resources, _ := step.Input["resources"].([]interface{})
+	return len(resources)
+}
+
+// NormalizeFieldPath strips array indices, wildcards and bracketed map keys from a
+// field path so it can be correlated with schema field paths, e.g.
+// spec.subnets[0].cidr and spec.subnets[*].cidr both become spec.subnets.cidr.
+// Paths that don't parse are returned unchanged.
+func NormalizeFieldPath(path string) string {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return path
 	}
 
-	return buf.String(), nil
+	keys := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if !seg.isIndex && !seg.bracketed {
+			keys = append(keys, seg.key)
+		}
+	}
+	return strings.Join(keys, ".")
 }
 
 // Helper functions
@@ -341,22 +1103,63 @@ func getString(m map[string]interface{}, key string) string {
 	return ""
 }
 
+// scalarString returns the value of key formatted as a string, so numbers and
+// booleans in function input aren't lost
+func scalarString(m map[string]interface{}, key string) string {
+	if v, ok := m[key]; ok && v != nil {
+		return formatValue(v)
+	}
+	return ""
+}
+
+// getStringFromMap returns the string at a field path, or "" when the path doesn't
+// resolve to a string
 func getStringFromMap(m map[string]interface{}, key string) string {
-	keys := strings.Split(key, ".")
-	current := m
+	value, err := lookupPath(m, key)
+	if err != nil {
+		return ""
+	}
+	v, _ := value.(string)
+	return v
+}
 
-	for i, k := range keys {
-		if i == len(keys)-1 {
-			if v, ok := current[k].(string); ok {
-				return v
-			}
-			return ""
+// getMapFromMap returns the object at a field path, or nil when the path doesn't
+// resolve to an object
+func getMapFromMap(m map[string]interface{}, key string) map[string]interface{} {
+	value, err := lookupPath(m, key)
+	if err != nil {
+		return nil
+	}
+	result, _ := stringMap(value)
+	return result
+}
+
+// getStringSliceFromMap returns the strings of the list at a field path, or nil
+// when the path doesn't resolve to a list
+func getStringSliceFromMap(m map[string]interface{}, key string) []string {
+	value, err := lookupPath(m, key)
+	if err != nil {
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, item := range items {
+		if v, ok := item.(string); ok {
+			result = append(result, v)
 		}
-		if next, ok := current[k].(map[string]interface{}); ok {
-			current = next
-		} else {
-			return ""
+	}
+	return result
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
 		}
 	}
-	return ""
+	return false
 }
@@ -0,0 +1,85 @@
+package composition
+
+import (
+	"testing"
+)
+
+func TestFormatManagementPolicies(t *testing.T) {
+	tests := []struct {
+		policies []string
+		want     string
+	}{
+		{nil, "-"},
+		{[]string{"*"}, "`*`"},
+		{[]string{"Observe", "Create", "Update", "Delete", "LateInitialize"}, "`Observe`, `Create`, `Update`, `Delete`, `LateInitialize`"},
+		{[]string{"LateInitialize", "Delete", "Update", "Create", "Observe"}, "`LateInitialize`, `Delete`, `Update`, `Create`, `Observe`"},
+		{[]string{"Observe", "Create", "Update", "Delete"}, "`Observe`, `Create`, `Update`, `Delete` ⚠️ partial control"},
+		{[]string{"Observe"}, "`Observe` ⚠️ observe-only, never created or modified"},
+	}
+	for _, tt := range tests {
+		if got := formatManagementPolicies(tt.policies); got != tt.want {
+			t.Errorf("formatManagementPolicies(%q) = %q, want %q", tt.policies, got, tt.want)
+		}
+	}
+}
+
+func TestProviderFromAPIVersion(t *testing.T) {
+	tests := map[string]string{
+		"rds.aws.upbound.io/v1beta1":          "aws.upbound.io",
+		"ec2.aws.m.upbound.io/v1beta1":        "aws.upbound.io",
+		"kubernetes.crossplane.io/v1alpha2":   "kubernetes.crossplane.io",
+		"kubernetes.m.crossplane.io/v1alpha1": "kubernetes.crossplane.io",
+		"m.example.org/v1":                    "m.example.org",
+		"v1":                                  "Other",
+	}
+	for apiVersion, want := range tests {
+		if got := providerFromAPIVersion(apiVersion); got != want {
+			t.Errorf("providerFromAPIVersion(%q) = %q, want %q", apiVersion, got, want)
+		}
+	}
+}
+
+func TestProviderDocsURL(t *testing.T) {
+	const marketplace = "https://marketplace.upbound.io/providers/"
+	tests := []struct {
+		apiVersion, kind string
+		want             string
+	}{
+		{"rds.aws.upbound.io/v1beta1", "Instance", marketplace + "upbound/provider-aws-rds/latest/resources/rds.aws.upbound.io/Instance/v1beta1"},
+		{"ec2.aws.m.upbound.io/v1beta1", "VPC", marketplace + "upbound/provider-aws-ec2/latest/resources/ec2.aws.m.upbound.io/VPC/v1beta1"},
+		{"helm.crossplane.io/v1beta1", "Release", marketplace + "crossplane-contrib/provider-helm/latest/resources/helm.crossplane.io/Release/v1beta1"},
+		{"kubernetes.m.crossplane.io/v1alpha1", "Object", marketplace + "crossplane-contrib/provider-kubernetes/latest/resources/kubernetes.m.crossplane.io/Object/v1alpha1"},
+		{"tf.upbound.io/v1beta1", "Workspace", marketplace + "upbound/provider-terraform/latest/resources/tf.upbound.io/Workspace/v1beta1"},
+		{"example.org/v1", "Thing", ""},
+	}
+	for _, tt := range tests {
+		if got := providerDocsURL(tt.apiVersion, tt.kind, ""); got != tt.want {
+			t.Errorf("providerDocsURL(%q, %q) = %q, want %q", tt.apiVersion, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestCombineDescribeEscapesPipes(t *testing.T) {
+	tests := []struct {
+		combine Combine
+		want    string
+	}{
+		{
+			Combine{Variables: []Variable{{FromFieldPath: "spec.a"}}, String: &StringFmt{Fmt: "%s|%s"}},
+			"Combine (string): `%s\\|%s`",
+		},
+		{
+			Combine{Strategy: "string", String: &StringFmt{Fmt: "a `b` | c"}},
+			"Combine (string): `` a `b` \\| c ``",
+		},
+		{
+			Combine{Strategy: "join", Variables: []Variable{{}, {}}, Settings: map[string]interface{}{"join": "|"}},
+			"Combine (join) of 2 variables: `\\|`",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.combine.describe(); got != tt.want {
+			t.Errorf("describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
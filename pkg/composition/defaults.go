@@ -0,0 +1,89 @@
+package composition
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FieldDefault is a value a composition supplies when an XR field is left unset:
+// the static base value at the target of a patch reading that field
+type FieldDefault struct {
+	Field       string // Normalized XR field path, e.g. spec.parameters.size
+	Value       string
+	Composition string
+	Resource    string
+	Target      string // Field path on the composed resource
+}
+
+// Defaults returns the values the composition's bases supply for XR fields that
+// are left unset. An optional FromCompositeFieldPath patch is skipped when its
+// source is missing, so the composed resource keeps the base value at the target.
+func (g *Generator) Defaults(comp *Composition) []FieldDefault {
+	var defaults []FieldDefault
+
+	add := func(resource string, base map[string]interface{}, patchType, from, to string, policy map[string]interface{}) {
+		if from == "" || to == "" || (patchType != "" && patchType != "FromCompositeFieldPath") {
+			return
+		}
+		if getString(policy, "fromFieldPath") == "Required" {
+			return
+		}
+		value, ok := valueAtPath(base, to)
+		if !ok {
+			return
+		}
+		defaults = append(defaults, FieldDefault{
+			Field:       NormalizeFieldPath(from),
+			Value:       formatValue(value),
+			Composition: getString(comp.Metadata, "name"),
+			Resource:    resource,
+			Target:      to,
+		})
+	}
+
+	for _, res := range comp.Spec.Resources {
+		for _, p := range res.Patches {
+			add(res.Name, res.Base, p.Type, p.FromFieldPath, p.ToFieldPath, p.Policy)
+		}
+	}
+
+	for _, step := range comp.Spec.Pipeline {
+		items, _ := step.Input["resources"].([]interface{})
+		for _, item := range items {
+			resMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			base, _ := resMap["base"].(map[string]interface{})
+			patches, _ := resMap["patches"].([]interface{})
+			for _, p := range patches {
+				patchMap, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				policy, _ := patchMap["policy"].(map[string]interface{})
+				add(getString(resMap, "name"), base, getString(patchMap, "type"),
+					getString(patchMap, "fromFieldPath"), getString(patchMap, "toFieldPath"), policy)
+			}
+		}
+	}
+
+	return defaults
+}
+
+// valueAtPath returns the non-nil value at a field path
+func valueAtPath(m map[string]interface{}, path string) (interface{}, bool) {
+	value, err := lookupPath(m, path)
+	return value, err == nil && value != nil
+}
+
+// formatValue formats a base value for display, using JSON for objects and lists
+func formatValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
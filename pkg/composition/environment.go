@@ -0,0 +1,105 @@
+package composition
+
+import "sort"
+
+// compositeReader is the ReadBy of environment patches, which patch the composite
+const compositeReader = "composite (environment patch)"
+
+// environmentInputs collects the environment keys read by resource patches and by
+// the environment patches of the composition or of a pipeline step's input
+func (g *Generator) environmentInputs(comp *Composition) []EnvironmentInput {
+	var inputs []EnvironmentInput
+
+	addResource := func(resource string, patches []Patch) {
+		for _, p := range patches {
+			for _, key := range environmentSources(p.Type, p.FromFieldPath, p.Combine) {
+				inputs = append(inputs, EnvironmentInput{Key: key, ReadBy: resource, Target: p.ToFieldPath})
+			}
+		}
+	}
+	addComposite := func(patches []Patch) {
+		for _, p := range patches {
+			for _, key := range environmentPatchSources(p.Type, p.FromFieldPath, p.Combine) {
+				inputs = append(inputs, EnvironmentInput{Key: key, ReadBy: compositeReader, Target: p.ToFieldPath})
+			}
+		}
+	}
+
+	if comp.Spec.Environment != nil {
+		addComposite(comp.Spec.Environment.Patches)
+	}
+	for _, res := range comp.Spec.Resources {
+		addResource(res.Name, res.Patches)
+	}
+	for _, step := range comp.Spec.Pipeline {
+		items, _ := step.Input["resources"].([]interface{})
+		for _, item := range items {
+			if resMap, ok := item.(map[string]interface{}); ok {
+				raw, _ := resMap["patches"].([]interface{})
+				addResource(getString(resMap, "name"), patchesFromInterface(raw))
+			}
+		}
+		if env := getMapFromMap(step.Input, "environment"); env != nil {
+			raw, _ := env["patches"].([]interface{})
+			addComposite(patchesFromInterface(raw))
+		}
+	}
+
+	sort.SliceStable(inputs, func(i, j int) bool {
+		return inputs[i].Key < inputs[j].Key
+	})
+	return inputs
+}
+
+// environmentSources returns the environment field paths a resource patch of patchType reads
+func environmentSources(patchType, fromFieldPath string, combine *Combine) []string {
+	switch patchType {
+	case "FromEnvironmentFieldPath":
+		if fromFieldPath != "" {
+			return []string{fromFieldPath}
+		}
+	case "CombineFromEnvironment":
+		if combine != nil {
+			return combine.sources()
+		}
+	}
+	return nil
+}
+
+// environmentPatchSources returns the environment field paths an environment patch
+// of patchType reads; the other environment patches write to the environment
+func environmentPatchSources(patchType, fromFieldPath string, combine *Combine) []string {
+	switch patchType {
+	case "ToCompositeFieldPath":
+		if fromFieldPath != "" {
+			return []string{fromFieldPath}
+		}
+	case "CombineToComposite":
+		if combine != nil {
+			return combine.sources()
+		}
+	}
+	return nil
+}
+
+// patchesFromInterface converts patches parsed as generic maps, keeping the fields
+// that say what a patch reads and writes
+func patchesFromInterface(raw []interface{}) []Patch {
+	var patches []Patch
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p := Patch{
+			Type:          getString(m, "type"),
+			FromFieldPath: getString(m, "fromFieldPath"),
+			ToFieldPath:   getString(m, "toFieldPath"),
+		}
+		if c, ok := m["combine"].(map[string]interface{}); ok {
+			p.Combine = combineFromMap(c)
+		}
+		patches = append(patches, p)
+	}
+	return patches
+}
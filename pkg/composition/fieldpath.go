@@ -0,0 +1,120 @@
+package composition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a field path: a map key or an array index
+type pathSegment struct {
+	key       string
+	index     int
+	isIndex   bool
+	bracketed bool // a key written in brackets, e.g. labels[app] or tags[*]
+}
+
+// parseFieldPath splits a field path into map keys and array indices, e.g.
+// spec.forProvider.tags[0] into spec, forProvider, tags and 0. Bracketed keys such
+// as metadata.labels[app.kubernetes.io/name], quoted or not, are kept whole, and
+// a wildcard such as tags[*] is a bracketed key of *.
+func parseFieldPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			inner, quoted, n, err := bracketContent(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("field path %q: %w", path, err)
+			}
+			i += n
+			if n, err := strconv.Atoi(inner); err == nil && !quoted {
+				if n < 0 {
+					return nil, fmt.Errorf("field path %q: negative index %d", path, n)
+				}
+				segments = append(segments, pathSegment{index: n, isIndex: true})
+				continue
+			}
+			segments = append(segments, pathSegment{key: inner, bracketed: true})
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, pathSegment{key: path[i : i+end]})
+			i += end
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty field path")
+	}
+	return segments, nil
+}
+
+// bracketContent returns the content of the bracket s starts with, unquoted, and
+// the length of the bracket. A quoted key may contain dots and brackets, and is
+// never an index.
+func bracketContent(s string) (content string, quoted bool, n int, err error) {
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		end := strings.IndexByte(s[2:], s[1])
+		if end < 0 || 3+end >= len(s) || s[3+end] != ']' {
+			return "", false, 0, fmt.Errorf("unclosed quoted key")
+		}
+		return s[2 : 2+end], true, end + 4, nil
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return "", false, 0, fmt.Errorf("unclosed [")
+	}
+	return s[1:end], false, end + 1, nil
+}
+
+// lookupPath returns the value at a field path. It traverses both the
+// map[string]interface{} and map[interface{}]interface{} maps YAML decoders
+// produce, and numeric array indices.
+func lookupPath(m map[string]interface{}, path string) (interface{}, error) {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var current interface{} = m
+	for _, seg := range segments {
+		var ok bool
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, ok = node[seg.key]
+			ok = ok && !seg.isIndex
+		case map[interface{}]interface{}:
+			current, ok = node[seg.key]
+			ok = ok && !seg.isIndex
+		case []interface{}:
+			ok = seg.isIndex && seg.index < len(node)
+			if ok {
+				current = node[seg.index]
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("field path %q not found", path)
+		}
+	}
+	return current, nil
+}
+
+// stringMap returns v as a map[string]interface{}, converting the keys of a
+// map[interface{}]interface{}
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for k, value := range m {
+			converted[fmt.Sprint(k)] = value
+		}
+		return converted, true
+	}
+	return nil, false
+}
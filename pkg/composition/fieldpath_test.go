@@ -0,0 +1,125 @@
+package composition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []pathSegment
+	}{
+		{"spec.region", []pathSegment{{key: "spec"}, {key: "region"}}},
+		{"spec.tags[0]", []pathSegment{{key: "spec"}, {key: "tags"}, {index: 0, isIndex: true}}},
+		{"spec.subnets[2].cidr", []pathSegment{{key: "spec"}, {key: "subnets"}, {index: 2, isIndex: true}, {key: "cidr"}}},
+		{"spec.subnets[*].cidr", []pathSegment{{key: "spec"}, {key: "subnets"}, {key: "*", bracketed: true}, {key: "cidr"}}},
+		{"matrix[0][1]", []pathSegment{{key: "matrix"}, {index: 0, isIndex: true}, {index: 1, isIndex: true}}},
+		{"metadata.labels[app.kubernetes.io/name]", []pathSegment{{key: "metadata"}, {key: "labels"}, {key: "app.kubernetes.io/name", bracketed: true}}},
+		{`metadata.annotations["example.org/x"]`, []pathSegment{{key: "metadata"}, {key: "annotations"}, {key: "example.org/x", bracketed: true}}},
+		{`data['a]b']`, []pathSegment{{key: "data"}, {key: "a]b", bracketed: true}}},
+		{`data["1"]`, []pathSegment{{key: "data"}, {key: "1", bracketed: true}}},
+	}
+	for _, tt := range tests {
+		got, err := parseFieldPath(tt.path)
+		if err != nil {
+			t.Errorf("parseFieldPath(%q) error = %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFieldPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseFieldPathErrors(t *testing.T) {
+	for _, path := range []string{"", ".", "spec.tags[0", "spec.tags[-1]", `data["a]`} {
+		if got, err := parseFieldPath(path); err == nil {
+			t.Errorf("parseFieldPath(%q) = %+v, want an error", path, got)
+		}
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	m := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"forProvider": map[interface{}]interface{}{
+				"tags": []interface{}{"first", "second"},
+				"subnets": []interface{}{
+					map[string]interface{}{"cidr": "10.0.0.0/24"},
+				},
+			},
+		},
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "db"},
+		},
+	}
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{path: "spec.forProvider.tags[1]", want: "second"},
+		{path: "spec.forProvider.subnets[0].cidr", want: "10.0.0.0/24"},
+		{path: "metadata.labels[app.kubernetes.io/name]", want: "db"},
+		{path: `metadata.labels["app.kubernetes.io/name"]`, want: "db"},
+		{path: "spec.forProvider.tags[2]", wantErr: true},
+		{path: "spec.forProvider.tags.first", wantErr: true},
+		{path: "spec.forProvider[0]", wantErr: true},
+		{path: "spec.missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := lookupPath(m, tt.path)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupPath(%q) = %v, %v, want %v (error: %v)", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeFieldPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"spec.region", "spec.region"},
+		{"spec.subnets[0].cidr", "spec.subnets.cidr"},
+		{"spec.subnets[*].cidr", "spec.subnets.cidr"},
+		{"spec.tags[*]", "spec.tags"},
+		{"spec.matrix[0][1]", "spec.matrix"},
+		{"spec.rules[12].ports[*].number", "spec.rules.ports.number"},
+		{`metadata.labels["app.kubernetes.io/name"]`, "metadata.labels"},
+		{"spec.tags[0", "spec.tags[0"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeFieldPath(tt.path); got != tt.want {
+			t.Errorf("NormalizeFieldPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeTarget(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"metadata.name", "sets resource name"},
+		{"metadata.annotations[crossplane.io/external-name]", "sets external name"},
+		{`metadata.annotations["crossplane.io/external-name"]`, "sets external name"},
+		{`metadata.annotations["example.org/x"]`, "sets annotation `example.org/x`"},
+		{`metadata.annotations['example.org/x']`, "sets annotation `example.org/x`"},
+		{"metadata.labels[app.kubernetes.io/name]", "sets label `app.kubernetes.io/name`"},
+		{`metadata.labels["team"]`, "sets label `team`"},
+		{"metadata.labels.team", "sets label `team`"},
+		{"metadata.labels", ""},
+		{"metadata.labelsExtra", ""},
+		{`metadata.labels["team"].value`, ""},
+		{"spec.forProvider.region", ""},
+	}
+	for _, tt := range tests {
+		if got := describeTarget(tt.path); got != tt.want {
+			t.Errorf("describeTarget(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
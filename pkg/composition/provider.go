@@ -0,0 +1,49 @@
+package composition
+
+import "strings"
+
+// checkProviderSchema annotates each patch with whether its target is required
+// by the resource's provider CRD, and lists the required provider fields that
+// are neither set in the base nor patched. Resources whose CRD isn't loaded are
+// left untouched.
+func (g *Generator) checkProviderSchema(resource *ManagedResource, base map[string]interface{}, patches []PatchInfo, opts Options) {
+	schema, ok := opts.ProviderCRDs.Lookup(resource.APIVersion, resource.Kind)
+	if !ok {
+		return
+	}
+
+	var targets []string
+	for i := range patches {
+		target := NormalizeFieldPath(patches[i].MappedTo)
+		if target == "" || strings.HasPrefix(target, "metadata.") || strings.HasPrefix(target, "status.") {
+			continue
+		}
+		targets = append(targets, target)
+
+		switch info := schema.Field(target); {
+		case !info.Found:
+			patches[i].ProviderField = "⚠️ not in CRD"
+		case info.Required:
+			patches[i].ProviderField = "required"
+		default:
+			patches[i].ProviderField = "optional"
+		}
+	}
+
+	for _, path := range schema.RequiredPaths("spec") {
+		if _, ok := valueAtPath(base, path); ok || patchesPath(targets, path) {
+			continue
+		}
+		resource.UnsetRequired = append(resource.UnsetRequired, path)
+	}
+}
+
+// patchesPath reports whether any patch target sets path or one of its parents
+func patchesPath(targets []string, path string) bool {
+	for _, t := range targets {
+		if t == path || strings.HasPrefix(path, t+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package composition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownProviders maps API groups of single-group providers to their package
+// on the Upbound marketplace
+var knownProviders = map[string]string{
+	"helm.crossplane.io":       "crossplane-contrib/provider-helm",
+	"kubernetes.crossplane.io": "crossplane-contrib/provider-kubernetes",
+	"http.crossplane.io":       "crossplane-contrib/provider-http",
+	"sql.crossplane.io":        "crossplane-contrib/provider-sql",
+	"tf.upbound.io":            "upbound/provider-terraform",
+}
+
+// providerDocsURL returns the documentation page of a managed resource kind, or
+// "" when its provider isn't known. Namespaced .m. groups link to the provider of
+// their cluster-scoped group. A custom URL template takes precedence over the
+// known providers; it may use {group}, {version} and {kind} placeholders.
+func providerDocsURL(apiVersion, kind, custom string) string {
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found || group == "" || version == "" || kind == "" {
+		return ""
+	}
+
+	if custom != "" {
+		return strings.NewReplacer("{group}", group, "{version}", version, "{kind}", kind).Replace(custom)
+	}
+
+	var pkg string
+	cluster := clusterGroup(group)
+	switch labels := strings.Split(cluster, "."); {
+	case knownProviders[cluster] != "":
+		pkg = knownProviders[cluster]
+	case len(labels) == 4 && labels[2] == "upbound" && labels[3] == "io":
+		// Family providers, e.g. rds.aws.upbound.io is served by provider-aws-rds
+		pkg = fmt.Sprintf("upbound/provider-%s-%s", labels[1], labels[0])
+	default:
+		return ""
+	}
+
+	return fmt.Sprintf("https://marketplace.upbound.io/providers/%s/latest/resources/%s/%s/%s", pkg, group, kind, version)
+}
+
+// kindLinker returns a template func rendering a resource kind as a link to its
+// provider docs, or as plain text when linking is off or the provider is unknown
+func kindLinker(opts Options) func(ManagedResource) string {
+	return func(r ManagedResource) string {
+		if !opts.ProviderDocs && opts.ProviderDocsURL == "" {
+			return r.Kind
+		}
+		url := providerDocsURL(r.APIVersion, r.Kind, opts.ProviderDocsURL)
+		if url == "" {
+			return r.Kind
+		}
+		return fmt.Sprintf("[%s](%s)", r.Kind, url)
+	}
+}
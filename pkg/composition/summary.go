@@ -0,0 +1,45 @@
+package composition
+
+import "fmt"
+
+// Summary is what a claim consumer needs to know about a composition: when the
+// composite becomes ready and which connection secret keys it publishes
+type Summary struct {
+	Name           string
+	Readiness      []string
+	ConnectionKeys []string
+}
+
+// Summarize summarizes the readiness behaviour and connection secret keys of a composition
+func (g *Generator) Summarize(comp *Composition) Summary {
+	var resources []ManagedResource
+	if comp.Spec.Mode == "Pipeline" && len(comp.Spec.Pipeline) > 0 {
+		resources = g.extractPipelineResources(comp, Options{})
+	} else {
+		resources = g.extractResources(comp.Spec.Resources, Options{})
+	}
+
+	summary := Summary{Name: getString(comp.Metadata, "name")}
+
+	switch step := autoReadyStep(comp); {
+	case step != nil:
+		summary.Readiness = append(summary.Readiness, fmt.Sprintf("Ready once every composed resource is ready (step `%s`)", step.Step))
+	case comp.Spec.Mode == "Pipeline":
+		summary.Readiness = append(summary.Readiness, "Readiness is reported by the pipeline's functions")
+	default:
+		summary.Readiness = append(summary.Readiness, "Ready once every composed resource is ready")
+	}
+
+	for _, r := range resources {
+		for _, check := range r.ReadinessChecks {
+			summary.Readiness = append(summary.Readiness, fmt.Sprintf("`%s` is ready when %s", r.Name, check))
+		}
+		for _, cd := range r.ConnectionDetails {
+			if !contains(summary.ConnectionKeys, cd.Name) {
+				summary.ConnectionKeys = append(summary.ConnectionKeys, cd.Name)
+			}
+		}
+	}
+
+	return summary
+}
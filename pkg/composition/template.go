@@ -0,0 +1,137 @@
+package composition
+
+// compositionSections are the sections of a composition document in render order
+var compositionSections = []string{"header", "resources", "pipeline", "environment", "environment-inputs", "connections", "credentials", "readiness", "details", "mappings"}
+
+// compositionTemplate defines each section of a composition document as a named template.
+// Sections are executed one by one, so text between the definitions is ignored.
+const compositionTemplate = `{{ define "header" }}# {{ .Composition.Spec.CompositeTypeRef.Kind }} Composition
+
+**Composition Name:** {{ .Name }}  
+**Composite Type:** {{ .Composition.Spec.CompositeTypeRef.APIVersion }}/{{ .Composition.Spec.CompositeTypeRef.Kind }}  
+{{ if .Composition.Spec.Mode }}**Mode:** {{ .Composition.Spec.Mode }}{{ end }}
+{{ end }}
+
+{{ define "resources" }}
+## Managed Resources
+
+This composition creates {{ len .Resources }} managed resource(s){{ if .PreserveOrder }}, listed in the order they are declared{{ end }}:
+
+{{ range .ResourceGroups }}{{ if .Provider }}
+### {{ .Provider }}
+
+{{ end -}}
+| Resource Name | Kind | API Version |{{ if $.ShowBase }} In-Cluster Name |{{ end }}{{ if $.HasManagementPolicies }} Management Policies |{{ end }}
+|---------------|------|-------------|{{ if $.ShowBase }}-----------------|{{ end }}{{ if $.HasManagementPolicies }}---------------------|{{ end }}
+{{ range .Resources -}}
+| {{ .Name }} | {{ kindLink . }} | {{ .APIVersion }} |{{ if $.ShowBase }} {{ .ClusterName }} |{{ end }}{{ if $.HasManagementPolicies }} {{ managementPolicies .ManagementPolicies }} |{{ end }}
+{{ end }}{{ end }}
+{{ end }}
+
+{{ define "pipeline" }}{{ if and (eq .Composition.Spec.Mode "Pipeline") .Composition.Spec.Pipeline }}
+## Pipeline Steps
+
+The composition runs these functions in order:
+
+| # | Step | Function | Resources |
+|---|------|----------|-----------|
+{{ range $i, $s := .Composition.Spec.Pipeline -}}
+| {{ inc $i }} | {{ $s.Step }} | {{ $s.FunctionRef.Name }} | {{ with stepResources $s }}{{ . }}{{ else }}-{{ end }} |
+{{ end }}
+{{ end }}{{ end }}
+
+{{ define "environment" }}{{ if .EnvironmentConfigs }}
+## Environment Configs
+
+This composition merges the following EnvironmentConfigs into its environment:
+
+| Type | Config | Selector Labels |
+|------|--------|-----------------|
+{{ range .EnvironmentConfigs -}}
+| {{ .Type }} | {{ .Config }} | {{ .Labels }} |
+{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "environment-inputs" }}{{ if .EnvironmentInputs }}
+## Environment Inputs
+
+This composition reads these keys from its environment, so they must be set by an EnvironmentConfig or an earlier function:
+
+| Environment Key | Read By | Target |
+|-----------------|---------|--------|
+{{ range .EnvironmentInputs -}}
+| ` + "`{{ .Key }}`" + ` | {{ .ReadBy }} | ` + "`{{ .Target }}`" + ` |
+{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "connections" }}{{ if .HasConnectionDetails }}
+## Connection Details
+{{ range .Resources }}{{ if .ConnectionDetails }}
+### {{ .Name }} ({{ .Kind }})
+
+| Name | Source Type | Source |
+|------|-------------|--------|
+{{ range .ConnectionDetails -}}
+| {{ .Name }} | {{ .SourceType }} | {{ .Source }} |
+{{ end }}{{ end }}{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "credentials" }}{{ if .CredentialSteps }}
+## Pipeline Credentials
+
+The following pipeline steps are given credentials and may read secrets or cluster state:
+
+| Step | Function | Credentials |
+|------|----------|-------------|
+{{ range .CredentialSteps -}}
+| {{ .Step }} | {{ .FunctionRef.Name }} | {{ credentials .Credentials }} |
+{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "readiness" }}{{ if or .AutoReady .HasReadinessChecks }}
+## Readiness
+{{ with .AutoReady }}
+**Readiness:** automatic (all composed resources must be Ready), via step ` + "`{{ .Step }}`" + ` (` + "`{{ .FunctionRef.Name }}`" + `).
+{{ end }}{{ if .HasReadinessChecks }}
+| Resource Name | Readiness Checks |
+|---------------|------------------|
+{{ range .Resources }}{{ if .ReadinessChecks -}}
+| {{ .Name }} | {{ join .ReadinessChecks ", " }} |
+{{ end }}{{ end }}{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "details" }}{{ if .ShowBase }}
+## Resource Details
+{{ range .Resources }}
+### {{ .Name }} ({{ .Kind }})
+
+- **Provider Config:** {{ .ProviderConfig }}
+- **Labels:** {{ if .Labels }}{{ join .Labels ", " }}{{ else }}-{{ end }}
+{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "mappings" }}{{ if .ShowPatches }}
+## Field Mappings
+{{ range .Resources }}
+### {{ .Name }} ({{ .Kind }})
+{{ if .Patches }}
+| XRD Field | Mapped To | Transformation |{{ if $.ProviderCRDs }} Provider Field |{{ end }}
+|-----------|-----------|----------------|{{ if $.ProviderCRDs }}----------------|{{ end }}
+{{ range .Patches -}}
+| {{ if .XRDField }}{{ cell .XRDField }}{{ else }}-{{ end }}{{ with .Policy }} ({{ . }}){{ end }}{{ with .UnknownFields }} ⚠️ not in XRD: {{ range $i, $f := . }}{{ if $i }}, {{ end }}` + "`{{ $f }}`" + `{{ end }}{{ end }} | {{ cell .MappedTo }}{{ if .TargetNote }} ({{ .TargetNote }}){{ end }} | {{ .Transformation }} |{{ if $.ProviderCRDs }} {{ if .ProviderField }}{{ .ProviderField }}{{ else }}-{{ end }} |{{ end }}
+{{ end }}
+{{ else }}
+No patches defined.
+{{ end }}{{ with .UnsetRequired }}
+> **Not set:** required by the provider CRD but neither set in the base nor patched: {{ range $i, $p := . }}{{ if $i }}, {{ end }}` + "`{{ $p }}`" + `{{ end }}
+{{ end }}
+{{ end }}
+{{ end }}
+{{ end }}
+`
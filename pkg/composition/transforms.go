@@ -0,0 +1,100 @@
+package composition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/michielvha/crossplane-docs/pkg/section"
+)
+
+// describeTransforms summarizes a patch's transforms in the order they apply,
+// e.g. map{dev→t3.small, prod→m5.large} → string format "x-%s"
+func describeTransforms(transforms []interface{}) string {
+	var parts []string
+	for _, t := range transforms {
+		if m, ok := t.(map[string]interface{}); ok {
+			parts = append(parts, describeTransform(m))
+		}
+	}
+	return section.EscapeTableCell(strings.Join(parts, " → "))
+}
+
+// describeTransform summarizes a single transform
+func describeTransform(t map[string]interface{}) string {
+	kind := getString(t, "type")
+	settings, _ := t[kind].(map[string]interface{})
+
+	switch kind {
+	case "map":
+		keys := make([]string, 0, len(settings))
+		for k := range settings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s→%s", k, formatValue(settings[k])))
+		}
+		return "map{" + strings.Join(pairs, ", ") + "}"
+
+	case "match":
+		patterns, _ := settings["patterns"].([]interface{})
+		desc := fmt.Sprintf("match (%d pattern(s)", len(patterns))
+		if fallback, ok := settings["fallbackValue"]; ok && fallback != nil {
+			desc += fmt.Sprintf(", fallback %s", formatValue(fallback))
+		}
+		return desc + ")"
+
+	case "string":
+		return describeStringTransform(settings)
+
+	case "math":
+		return describeMathTransform(settings)
+
+	case "convert":
+		desc := "convert to " + getString(settings, "toType")
+		if format := getString(settings, "format"); format != "" {
+			desc += fmt.Sprintf(" (%s)", format)
+		}
+		return desc
+	}
+
+	return kind
+}
+
+// describeStringTransform summarizes a string transform. Transforms without a
+// type are the legacy form of Format.
+func describeStringTransform(s map[string]interface{}) string {
+	switch t := getString(s, "type"); t {
+	case "", "Format":
+		return fmt.Sprintf("string format %q", getString(s, "fmt"))
+	case "Convert":
+		return "string convert " + getString(s, "convert")
+	case "TrimPrefix", "TrimSuffix":
+		return fmt.Sprintf("string %s %q", strings.ToLower(t[:1])+t[1:], getString(s, "trim"))
+	case "Regexp":
+		regexp, _ := s["regexp"].(map[string]interface{})
+		return fmt.Sprintf("string regexp %q", getString(regexp, "match"))
+	case "Join":
+		join, _ := s["join"].(map[string]interface{})
+		return fmt.Sprintf("string join %q", getString(join, "separator"))
+	default:
+		return "string " + t
+	}
+}
+
+// describeMathTransform summarizes a math transform, including the legacy form
+// that only sets multiply
+func describeMathTransform(m map[string]interface{}) string {
+	switch t := getString(m, "type"); t {
+	case "", "Multiply":
+		return fmt.Sprintf("math *%s", formatValue(m["multiply"]))
+	case "ClampMin":
+		return fmt.Sprintf("math clampMin %s", formatValue(m["clampMin"]))
+	case "ClampMax":
+		return fmt.Sprintf("math clampMax %s", formatValue(m["clampMax"]))
+	default:
+		return "math " + t
+	}
+}
@@ -0,0 +1,73 @@
+package composition
+
+import "strings"
+
+// crossplaneFields are the XR fields Crossplane manages itself, which an XRD's
+// schema doesn't declare
+var crossplaneFields = []string{
+	"spec.compositionRef",
+	"spec.compositionSelector",
+	"spec.compositionRevisionRef",
+	"spec.compositionRevisionSelector",
+	"spec.compositionUpdatePolicy",
+	"spec.claimRef",
+	"spec.resourceRefs",
+	"spec.environmentConfigRefs",
+	"spec.writeConnectionSecretToRef",
+	"spec.publishConnectionDetailsTo",
+	"spec.crossplane",
+	"status.conditions",
+	"status.connectionDetails",
+	"status.claimConditionTypes",
+}
+
+// xrSources returns the XR field paths a patch of patchType reads
+func xrSources(patchType, fromFieldPath string, combine *Combine) []string {
+	switch patchType {
+	case "", "FromCompositeFieldPath":
+		if fromFieldPath != "" {
+			return []string{fromFieldPath}
+		}
+	case "CombineFromComposite":
+		if combine != nil {
+			return combine.sources()
+		}
+	}
+	return nil
+}
+
+// checkXRDFields flags patch sources that don't resolve to a field of the
+// composite's XRD. Only spec and status paths are checked, as metadata isn't
+// part of the XRD schema.
+func (g *Generator) checkXRDFields(comp *Composition, resources []ManagedResource, opts Options) {
+	schema, ok := opts.XRD.Lookup(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)
+	if !ok {
+		return
+	}
+
+	for i := range resources {
+		for j := range resources[i].Patches {
+			p := &resources[i].Patches[j]
+			for _, source := range p.xrSources {
+				path := NormalizeFieldPath(source)
+				if !strings.HasPrefix(path, "spec.") && !strings.HasPrefix(path, "status.") {
+					continue
+				}
+				if crossplaneField(path) || schema.Field(path).Found {
+					continue
+				}
+				p.UnknownFields = append(p.UnknownFields, source)
+			}
+		}
+	}
+}
+
+// crossplaneField reports whether path is, or is below, a field Crossplane manages
+func crossplaneField(path string) bool {
+	for _, f := range crossplaneFields {
+		if path == f || strings.HasPrefix(path, f+".") {
+			return true
+		}
+	}
+	return false
+}
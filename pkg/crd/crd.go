@@ -0,0 +1,272 @@
+package crd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CRD represents the parts of a CustomResourceDefinition needed to look up fields
+type CRD struct {
+	Kind string `yaml:"kind"`
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []Version `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// Version is a served version of a CRD
+type Version struct {
+	Name   string `yaml:"name"`
+	Served bool   `yaml:"served"`
+	Schema struct {
+		OpenAPIV3Schema Schema `yaml:"openAPIV3Schema"`
+	} `yaml:"schema"`
+}
+
+// Schema is the subset of an OpenAPI v3 schema used to resolve field paths
+type Schema struct {
+	Type       string            `yaml:"type"`
+	Required   []string          `yaml:"required,omitempty"`
+	Properties map[string]Schema `yaml:"properties,omitempty"`
+	Items      *Schema           `yaml:"items,omitempty"`
+
+	// PreserveUnknownFields allows any field below the schema
+	PreserveUnknownFields bool `yaml:"x-kubernetes-preserve-unknown-fields,omitempty"`
+
+	// AdditionalProperties may also be a boolean, which decodes to nil
+	AdditionalProperties *Schema `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a schema, accepting boolean additionalProperties
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+
+	// Schemas reused through YAML aliases are read from the node they refer to
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		for value.Kind == yaml.AliasNode && value.Alias != nil {
+			value = value.Alias
+		}
+		if node.Content[i].Value == "additionalProperties" && value.Kind == yaml.MappingNode {
+			var ap Schema
+			if err := value.Decode(&ap); err != nil {
+				return err
+			}
+			s.AdditionalProperties = &ap
+		}
+	}
+	return nil
+}
+
+// Set holds provider CRDs indexed by group and kind
+type Set struct {
+	crds map[string]*CRD
+}
+
+// FieldInfo describes a field path looked up in a CRD
+type FieldInfo struct {
+	Found    bool // The path exists in the schema
+	Required bool // The field is required by its parent object
+}
+
+// LoadDir loads every CustomResourceDefinition in the YAML files of dir.
+// Other documents are skipped, so a directory of mixed manifests can be used.
+func LoadDir(dir string) (*Set, error) {
+	set := &Set{crds: make(map[string]*CRD)}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		defer f.Close()
+
+		return set.decode(f, path, "CustomResourceDefinition")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// LoadXRD loads the CompositeResourceDefinitions in file, so compositions can be
+// checked against the schema of the XR they compose. XRDs share the version
+// layout of CRDs, so the same lookups apply.
+func LoadXRD(file string) (*Set, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	set := &Set{crds: make(map[string]*CRD)}
+	if err := set.decode(f, file, "CompositeResourceDefinition"); err != nil {
+		return nil, err
+	}
+
+	if set.Len() == 0 {
+		return nil, fmt.Errorf("%s: no CompositeResourceDefinition found", file)
+	}
+	return set, nil
+}
+
+// decode adds the definitions of kind in the YAML documents read from r. The kind
+// of each document is checked before it's decoded as a definition, so documents of
+// other kinds are skipped whatever their shape and only malformed definitions fail.
+func (s *Set) decode(r io.Reader, name, kind string) error {
+	dec := yaml.NewDecoder(r)
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: failed to parse YAML: %w", name, err)
+		}
+
+		var header struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := node.Decode(&header); err != nil || header.Kind != kind {
+			continue
+		}
+
+		var c CRD
+		if err := node.Decode(&c); err != nil {
+			return fmt.Errorf("%s: malformed %s: %w", name, kind, err)
+		}
+		if c.Spec.Names.Kind != "" {
+			s.crds[key(c.Spec.Group, c.Spec.Names.Kind)] = &c
+		}
+	}
+}
+
+// Len returns the number of loaded CRDs
+func (s *Set) Len() int {
+	return len(s.crds)
+}
+
+// Lookup returns the schema for a resource's apiVersion and kind, preferring the
+// matching version and falling back to the first served one
+func (s *Set) Lookup(apiVersion, kind string) (*Schema, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	group, version, _ := strings.Cut(apiVersion, "/")
+	c, ok := s.crds[key(group, kind)]
+	if !ok {
+		return nil, false
+	}
+
+	var fallback *Schema
+	for i := range c.Spec.Versions {
+		v := &c.Spec.Versions[i]
+		if v.Name == version {
+			return &v.Schema.OpenAPIV3Schema, true
+		}
+		if fallback == nil && v.Served {
+			fallback = &v.Schema.OpenAPIV3Schema
+		}
+	}
+	return fallback, fallback != nil
+}
+
+// Field looks up a dotted field path, e.g. spec.forProvider.region. Array
+// items and map values are descended into transparently, so the path should
+// not contain indices.
+func (s *Schema) Field(path string) FieldInfo {
+	current := s
+	var info FieldInfo
+
+	for _, name := range strings.Split(path, ".") {
+		for current.Items != nil && current.Properties == nil {
+			current = current.Items
+		}
+
+		next, ok := current.Properties[name]
+		switch {
+		case ok:
+			info.Required = contains(current.Required, name)
+			current = &next
+		case current.AdditionalProperties != nil:
+			info.Required = false
+			current = current.AdditionalProperties
+		case current.PreserveUnknownFields:
+			return FieldInfo{Found: true}
+		default:
+			return FieldInfo{}
+		}
+	}
+
+	info.Found = true
+	return info
+}
+
+// RequiredPaths returns the dotted paths of the required leaf fields below
+// prefix, following only required objects
+func (s *Schema) RequiredPaths(prefix string) []string {
+	current := s
+	for _, name := range strings.Split(prefix, ".") {
+		next, ok := current.Properties[name]
+		if !ok {
+			return nil
+		}
+		current = &next
+	}
+
+	var paths []string
+	var walk func(schema *Schema, path string)
+	walk = func(schema *Schema, path string) {
+		for _, name := range schema.Required {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				continue
+			}
+			if len(prop.Required) > 0 && len(prop.Properties) > 0 {
+				walk(&prop, path+"."+name)
+			} else {
+				paths = append(paths, path+"."+name)
+			}
+		}
+	}
+	walk(current, prefix)
+
+	return paths
+}
+
+func key(group, kind string) string {
+	return group + "/" + kind
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package crd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const bucketCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.s3.aws.upbound.io
+spec:
+  group: s3.aws.upbound.io
+  names: {kind: Bucket}
+  versions:
+  - name: v1beta1
+    served: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: [forProvider]
+            properties:
+              forProvider:
+                type: object
+                properties:
+                  region: {type: string}
+`
+
+// writeFiles writes the named files to a new temporary directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDirSkipsOtherKinds(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"crds.yaml": bucketCRD + `---
+# A document of another kind whose spec doesn't fit the CRD layout
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+spec:
+  versions: not-a-list
+---
+---
+- a list document
+`,
+		"notes.txt": "not yaml: [",
+	})
+
+	set, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if set.Len() != 1 {
+		t.Fatalf("LoadDir() loaded %d CRDs, want 1", set.Len())
+	}
+
+	schema, ok := set.Lookup("s3.aws.upbound.io/v1beta1", "Bucket")
+	if !ok {
+		t.Fatal("Lookup() found no Bucket schema")
+	}
+	if info := schema.Field("spec.forProvider"); !info.Found || !info.Required {
+		t.Errorf("Field(spec.forProvider) = %+v, want found and required", info)
+	}
+}
+
+func TestLoadDirRejectsMalformedCRDs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"crd.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions: not-a-list
+`,
+	})
+
+	_, err := LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "malformed CustomResourceDefinition") {
+		t.Errorf("LoadDir() error = %v, want a malformed CRD error", err)
+	}
+}
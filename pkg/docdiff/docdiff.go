@@ -0,0 +1,147 @@
+package docdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+)
+
+// Markdown renders the field changes between two XRDs as a markdown changelog
+// suitable for a PR comment
+func Markdown(name string, result generator.DiffResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### Documentation changes: `%s`\n\n", name)
+	if len(result.Changes) == 0 {
+		b.WriteString("The generated documentation changed, but no fields were added, removed or modified.\n")
+		return b.String()
+	}
+
+	var added, removed, changed []generator.Change
+	for _, c := range result.Changes {
+		switch c.Kind {
+		case generator.ChangeAdded:
+			added = append(added, c)
+		case generator.ChangeRemoved:
+			removed = append(removed, c)
+		default:
+			changed = append(changed, c)
+		}
+	}
+
+	writeList := func(title string, changes []generator.Change, describe bool) {
+		if len(changes) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "**%s (%d):**\n\n", title, len(changes))
+		for _, c := range changes {
+			if describe {
+				fmt.Fprintf(&b, "- `%s`: %s\n", c.Path, c)
+			} else {
+				fmt.Fprintf(&b, "- `%s`\n", c.Path)
+			}
+		}
+		b.WriteString("\n")
+	}
+	writeList("Added fields", added, false)
+	writeList("Removed fields", removed, false)
+	writeList("Changed fields", changed, true)
+
+	return b.String()
+}
+
+// Lines renders a line diff of two documents, listing removed lines with "-"
+// and added lines with "+"
+func Lines(oldDoc, newDoc string) string {
+	a, b := strings.Split(oldDoc, "\n"), strings.Split(newDoc, "\n")
+
+	// Lines shared at the start and end need no alignment
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out strings.Builder
+	diffLines(&out, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	return out.String()
+}
+
+// diffLines writes the changes between a and b along a longest common
+// subsequence, found with Hirschberg's algorithm in memory linear in the
+// number of lines
+func diffLines(out *strings.Builder, a, b []string) {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			fmt.Fprintf(out, "+ %s\n", line)
+		}
+		return
+	case len(b) == 0:
+		for _, line := range a {
+			fmt.Fprintf(out, "- %s\n", line)
+		}
+		return
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				diffLines(out, nil, b[:j])
+				diffLines(out, nil, b[j+1:])
+				return
+			}
+		}
+		diffLines(out, a, nil)
+		diffLines(out, nil, b)
+		return
+	}
+
+	// Split b where the LCS of the first half of a with its start and of the
+	// second half with its end add up to the most
+	mid := len(a) / 2
+	head, tail := lcsPrefixes(a[:mid], b), lcsSuffixes(a[mid:], b)
+	split := 0
+	for j := range head {
+		if head[j]+tail[j] > head[split]+tail[split] {
+			split = j
+		}
+	}
+
+	diffLines(out, a[:mid], b[:split])
+	diffLines(out, a[mid:], b[split:])
+}
+
+// lcsPrefixes returns the LCS length of a with b[:j] for every j
+func lcsPrefixes(a, b []string) []int {
+	prev, curr := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// lcsSuffixes returns the LCS length of a with b[j:] for every j
+func lcsSuffixes(a, b []string) []int {
+	prev, curr := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				curr[j] = prev[j+1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j+1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
@@ -0,0 +1,78 @@
+package docdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+)
+
+func TestMarkdown(t *testing.T) {
+	result := generator.DiffResult{
+		OldVersion: "v1",
+		NewVersion: "v1",
+		Changes: []generator.Change{
+			{Path: "spec.networks.*.cidr", Kind: generator.ChangeRemoved, Severity: generator.SeverityBreaking},
+			{Path: "spec.region", Kind: generator.ChangeType, Severity: generator.SeverityBreaking, Old: "string", New: "integer"},
+			{Path: "spec.subnets[].zone", Kind: generator.ChangeAdded, Severity: generator.SeverityInfo, New: "string"},
+		},
+	}
+
+	want := "### Documentation changes: `xrd.yaml`\n\n" +
+		"**Added fields (1):**\n\n" +
+		"- `spec.subnets[].zone`\n\n" +
+		"**Removed fields (1):**\n\n" +
+		"- `spec.networks.*.cidr`\n\n" +
+		"**Changed fields (1):**\n\n" +
+		"- `spec.region`: type changed from string to integer\n\n"
+	if got := Markdown("xrd.yaml", result); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"equal", "a\nb\nc", "a\nb\nc", ""},
+		{"changed line", "a\nb\nc", "a\nx\nc", "- b\n+ x\n"},
+		{"added and removed", "a\nb\nc\nd", "b\nc\ne\nd", "- a\n+ e\n"},
+		{"replaced", "a\nb", "c\nd", "- a\n- b\n+ c\n+ d\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.old, tt.new); got != tt.want {
+				t.Errorf("Lines() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesLargeDocument(t *testing.T) {
+	// A table of 5000 rows with every other row changed
+	var old, new strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&old, "| spec.field%d | string |\n", i)
+		if i%2 == 0 {
+			fmt.Fprintf(&new, "| spec.field%d | integer |\n", i)
+		} else {
+			fmt.Fprintf(&new, "| spec.field%d | string |\n", i)
+		}
+	}
+
+	var removed, added int
+	for _, line := range strings.Split(Lines(old.String(), new.String()), "\n") {
+		switch {
+		case strings.HasPrefix(line, "- "):
+			removed++
+		case strings.HasPrefix(line, "+ "):
+			added++
+		}
+	}
+	if removed != 2500 || added != 2500 {
+		t.Errorf("Lines() removed %d and added %d lines, want 2500 each", removed, added)
+	}
+}
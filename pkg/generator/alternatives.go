@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// alternativeNotes explains the oneOf, anyOf and allOf branches of an object
+// schema, keyed by the property names each note applies to. Structural schemas
+// use the branches to constrain which properties are set, e.g. a oneOf of
+// {required: [subnetId]} and {required: [subnetSelector]}.
+func alternativeNotes(schema OpenAPISchema) map[string][]string {
+	notes := make(map[string][]string)
+
+	for _, group := range []struct {
+		label    string
+		branches []OpenAPISchema
+	}{
+		{"Exactly one of", schema.OneOf},
+		{"Any of", schema.AnyOf},
+		{"All of", schema.AllOf},
+	} {
+		var options []string
+		involved := make(map[string]bool)
+		for _, branch := range group.branches {
+			names := branchProperties(branch)
+			if len(names) == 0 {
+				continue
+			}
+			for _, name := range names {
+				involved[name] = true
+			}
+			options = append(options, "`"+strings.Join(names, "` + `")+"`")
+		}
+		if len(options) == 0 {
+			continue
+		}
+
+		note := fmt.Sprintf("%s: %s", group.label, strings.Join(options, ", "))
+		for name := range involved {
+			if _, ok := schema.Properties[name]; ok {
+				notes[name] = append(notes[name], note)
+			}
+		}
+	}
+
+	return notes
+}
+
+// branchProperties returns the sorted property names a composition branch
+// requires or constrains
+func branchProperties(branch OpenAPISchema) []string {
+	seen := make(map[string]bool)
+	for _, name := range branch.Required {
+		seen[name] = true
+	}
+	for name := range branch.Properties {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fieldReferencePattern matches backtick-wrapped dotted field paths in descriptions
+var fieldReferencePattern = regexp.MustCompile("`((?:spec|status)(?:\\.[A-Za-z0-9_*-]+)+)`")
+
+// assignAnchors gives every field a unique anchor derived from its path and returns
+// the anchor of each path. Paths that slug alike, such as spec.a-b and spec.a.b or
+// the items of lists and maps, get a numeric suffix that no other field uses.
+func assignAnchors(slugFunc func(string) string, fieldSets ...[]Field) map[string]string {
+	anchors := make(map[string]string)
+	used := make(map[string]int)
+
+	for _, fields := range fieldSets {
+		for i := range fields {
+			base := slugFunc(fields[i].Path)
+			slug := base
+			for used[slug] > 0 {
+				slug = fmt.Sprintf("%s-%d", base, used[base])
+				used[base]++
+			}
+			used[slug]++
+
+			fields[i].Anchor = slug
+			anchors[fields[i].Path] = slug
+		}
+	}
+
+	return anchors
+}
+
+// linkFieldReferences rewrites backtick-wrapped references to known field paths in
+// descriptions as links to the field anchors; unknown references are left as-is
+func linkFieldReferences(fields []Field, anchors map[string]string) {
+	for i := range fields {
+		fields[i].Description = fieldReferencePattern.ReplaceAllStringFunc(fields[i].Description, func(ref string) string {
+			path := strings.Trim(ref, "`")
+			if anchor, ok := anchors[path]; ok {
+				return fmt.Sprintf("[%s](#%s)", ref, anchor)
+			}
+			return ref
+		})
+	}
+}
+
+// slugStyle describes how text becomes an anchor slug. Letters and digits are
+// kept, lowercased; other characters are kept, become a hyphen or are dropped.
+type slugStyle struct {
+	keep     string // punctuation kept as-is
+	hyphen   string // characters that become a hyphen, or "" for every other character
+	collapse bool   // merge runs of hyphens into one and trim them from the ends
+}
+
+var (
+	// fieldSlugs are the explicit field anchors, the same in every flavor: each run
+	// of other characters, such as the dots of a path, becomes one hyphen
+	fieldSlugs = slugStyle{collapse: true}
+	// githubSlugs are the anchors GitHub gives headings: spaces become hyphens
+	// and other punctuation except - and _ is dropped
+	githubSlugs = slugStyle{keep: "-_", hyphen: " "}
+	// mkdocsSlugs are the anchors MkDocs' toc extension gives headings: punctuation
+	// is dropped and runs of spaces or hyphens become a single hyphen
+	mkdocsSlugs = slugStyle{keep: "_", hyphen: " -", collapse: true}
+)
+
+// slugify converts text to an anchor slug of the style
+func (s slugStyle) slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || strings.ContainsRune(s.keep, r):
+			b.WriteRune(r)
+			dash = false
+		case s.hyphen == "" || strings.ContainsRune(s.hyphen, r):
+			if s.collapse && (dash || b.Len() == 0) {
+				continue
+			}
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if s.collapse {
+		return strings.TrimSuffix(b.String(), "-")
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugStyles(t *testing.T) {
+	tests := []struct {
+		style slugStyle
+		text  string
+		want  string
+	}{
+		{fieldSlugs, "spec.forProvider.region", "spec-forprovider-region"},
+		{fieldSlugs, "spec.subnets[].cidr", "spec-subnets-cidr"},
+		{fieldSlugs, "v1.spec.node_pool", "v1-spec-node-pool"},
+		{githubSlugs, "Spec Fields", "spec-fields"},
+		{githubSlugs, "v1beta1 (storage)", "v1beta1-storage"},
+		{githubSlugs, "spec.a - b_c", "speca---b_c"},
+		{mkdocsSlugs, "Spec Fields", "spec-fields"},
+		{mkdocsSlugs, "v1beta1 (storage)", "v1beta1-storage"},
+		{mkdocsSlugs, "spec.a - b_c", "speca-b_c"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.slugify(tt.text); got != tt.want {
+			t.Errorf("%+v.slugify(%q) = %q, want %q", tt.style, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFieldAnchorsMatchAcrossFlavors(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      forProvider:
+        type: object
+        properties:
+          region: {type: string}
+`))
+
+	for _, flavor := range []string{"github", "techdocs"} {
+		doc, err := New().Generate(xrd, Options{Flavor: flavor, FieldAnchors: true, ShowNested: true})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if !strings.Contains(doc, `<a id="spec-forprovider-region"></a>`) {
+			t.Errorf("%s output has no spec-forprovider-region anchor:\n%s", flavor, doc)
+		}
+	}
+}
+
+func TestConsumerRequiredFieldsHaveAnchors(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    required: [forProvider]
+    properties:
+      forProvider:
+        type: object
+        required: [region]
+        properties:
+          region: {type: string}
+`))
+
+	for _, fullPaths := range []bool{false, true} {
+		doc, err := New().Generate(xrd, Options{Audience: "consumer", FieldAnchors: true, ShowNested: true, FullPaths: fullPaths})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if !strings.Contains(doc, `<a id="spec-forprovider-region"></a>`) {
+			t.Errorf("consumer output (full paths %v) has no spec-forprovider-region anchor:\n%s", fullPaths, doc)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package generator
+
+// BundledComposition summarizes a composition bundled with the XRD for consumers
+type BundledComposition struct {
+	Name           string
+	Readiness      []string
+	ConnectionKeys []string
+}
+
+// connectionKeys returns the connection secret keys a consumer receives: the
+// XRD's connectionSecretKeys when set, as Crossplane only propagates those,
+// otherwise every key published by the bundled compositions
+func connectionKeys(xrd *XRD, comps []BundledComposition) []string {
+	if len(xrd.Spec.ConnectionSecretKeys) > 0 {
+		return xrd.Spec.ConnectionSecretKeys
+	}
+
+	var keys []string
+	for _, c := range comps {
+		for _, k := range c.ConnectionKeys {
+			if !contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/michielvha/crossplane-docs/pkg/section"
+)
+
+// ValidationRule represents an x-kubernetes-validations entry
+type ValidationRule struct {
+	Path              string // Dotted path of the schema the rule is declared on
+	Rule              string
+	Message           string
+	MessageExpression string
+	Reason            string // Machine-readable failure category, e.g. FieldValueInvalid
+	FieldPath         string // Field the failure is attributed to, relative to Path
+}
+
+// celFieldPattern matches the self.<field> references in a CEL rule
+var celFieldPattern = regexp.MustCompile(`self\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// validationRules extracts the rules of a schema's x-kubernetes-validations
+func validationRules(schema OpenAPISchema) []ValidationRule {
+	var rules []ValidationRule
+	for _, v := range schema.XKubernetesValidations {
+		rule := ValidationRule{}
+		if s, ok := v["rule"].(string); ok {
+			rule.Rule = s
+		}
+		if s, ok := v["message"].(string); ok {
+			rule.Message = s
+		}
+		if s, ok := v["messageExpression"].(string); ok {
+			rule.MessageExpression = s
+		}
+		if s, ok := v["reason"].(string); ok {
+			rule.Reason = s
+		}
+		if s, ok := v["fieldPath"].(string); ok {
+			rule.FieldPath = s
+		}
+		if rule.Rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// collectValidationRules returns the validation rules declared on schema and
+// every schema below it, in field order
+func collectValidationRules(schema OpenAPISchema, path string) []ValidationRule {
+	var rules []ValidationRule
+	for _, rule := range validationRules(schema) {
+		rule.Path = path
+		rules = append(rules, rule)
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rules = append(rules, collectValidationRules(schema.Properties[name], joinPath(path, name))...)
+	}
+
+	if schema.Items != nil {
+		rules = append(rules, collectValidationRules(*schema.Items, path+"[*]")...)
+	}
+	if values, ok := schema.mapValues(); ok {
+		rules = append(rules, collectValidationRules(*values, joinPath(path, "*"))...)
+	}
+	return rules
+}
+
+// conditionalRequirements detects CEL rules on an object that encode conditional
+// requiredness (has() checks, or messages about required fields) and returns a note
+// for each of the object's properties the rule involves. CEL isn't evaluated; this
+// only hints that requiredness depends on other values.
+func conditionalRequirements(schema OpenAPISchema) map[string]string {
+	notes := make(map[string][]string)
+
+	for _, rule := range validationRules(schema) {
+		lowerMessage := strings.ToLower(rule.Message)
+		if !strings.Contains(rule.Rule, "has(") && !strings.Contains(lowerMessage, "required") {
+			continue
+		}
+
+		involved := make(map[string]bool)
+		for _, m := range celFieldPattern.FindAllStringSubmatch(rule.Rule, -1) {
+			involved[m[1]] = true
+		}
+
+		explanation := rule.Message
+		if explanation == "" {
+			explanation = "`" + rule.Rule + "`"
+		}
+		for name := range involved {
+			if _, ok := schema.Properties[name]; ok {
+				notes[name] = append(notes[name], explanation)
+			}
+		}
+	}
+
+	result := make(map[string]string, len(notes))
+	for name, explanations := range notes {
+		sort.Strings(explanations)
+		result[name] = fmt.Sprintf("Conditionally required: %s", section.EscapeTableCell(strings.Join(explanations, "; ")))
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package generator
+
+// ScopedField is a field Crossplane adds to only one of the claim and the composite
+type ScopedField struct {
+	Path        string
+	Description string
+}
+
+// claimOnlyFields are the fields Crossplane adds to claims but not to composites
+var claimOnlyFields = []ScopedField{
+	{"spec.resourceRef", "Reference to the composite resource bound to the claim"},
+	{"spec.compositeDeletePolicy", "Whether deleting the claim deletes the composite in the background or in the foreground"},
+}
+
+// compositeOnlyFields are the fields Crossplane adds to composites but not to claims
+var compositeOnlyFields = []ScopedField{
+	{"spec.claimRef", "Reference to the claim bound to the composite"},
+	{"spec.resourceRefs", "References to the composed resources"},
+	{"spec.environmentConfigRefs", "References to the EnvironmentConfigs selected for composition"},
+	{"spec.writeConnectionSecretToRef.namespace", "Namespace of the connection secret; a claim always writes it to its own namespace"},
+}
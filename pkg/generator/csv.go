@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// writeCSV writes the spec and status fields as CSV rows with their full dotted paths
+func (g *Generator) writeCSV(out io.Writer, specFields, statusFields []Field, excludeTypes []string) error {
+	w := csv.NewWriter(out)
+
+	rows := [][]string{{"path", "type", "required", "default", "constraints", "description"}}
+	for _, fields := range [][]Field{specFields, statusFields} {
+		for _, f := range excludeFieldTypes(g.flattenFields(fields), excludeTypes) {
+			rows = append(rows, []string{f.Path, f.Type, strconv.FormatBool(f.Required), f.Default, f.Constraints, f.Description})
+		}
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity classifies a change between two XRD versions
+type Severity string
+
+const (
+	SeverityBreaking Severity = "breaking" // may break existing claims and composites
+	SeverityReview   Severity = "review"   // may break them depending on their values
+	SeverityInfo     Severity = "info"     // compatible with existing claims and composites
+)
+
+// Severities lists the severities from most to least severe
+var Severities = []Severity{SeverityBreaking, SeverityReview, SeverityInfo}
+
+// ChangeKind is the kind of change to a field
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"
+	ChangeRemoved     ChangeKind = "removed"
+	ChangeType        ChangeKind = "type"
+	ChangeRequired    ChangeKind = "required"
+	ChangeOptional    ChangeKind = "optional"
+	ChangeConstraints ChangeKind = "constraints"
+)
+
+// Change is a change to a field between two XRD versions
+type Change struct {
+	Path     string     `json:"path"`
+	Kind     ChangeKind `json:"kind"`
+	Severity Severity   `json:"severity"`
+	Old      string     `json:"old,omitempty"` // Type or constraints before a type or constraints change
+	New      string     `json:"new,omitempty"` // Type or constraints after, or the type of an added field
+}
+
+// DiffResult holds the changes between two XRD versions, sorted by path
+type DiffResult struct {
+	OldVersion string   `json:"oldVersion"`
+	NewVersion string   `json:"newVersion"`
+	Changes    []Change `json:"changes"`
+}
+
+// Breaking reports whether any change is potentially breaking
+func (r DiffResult) Breaking() bool {
+	return len(r.BySeverity(SeverityBreaking)) > 0
+}
+
+// BySeverity returns the changes of the given severity
+func (r DiffResult) BySeverity(severity Severity) []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Severity == severity {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// String describes the change, e.g. "type changed from string to integer"
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		if c.Severity == SeverityBreaking {
+			return fmt.Sprintf("added as required (%s)", c.New)
+		}
+		return fmt.Sprintf("added (%s)", c.New)
+	case ChangeRemoved:
+		return "removed"
+	case ChangeType:
+		return fmt.Sprintf("type changed from %s to %s", c.Old, c.New)
+	case ChangeRequired:
+		return "now required"
+	case ChangeOptional:
+		return "no longer required"
+	case ChangeConstraints:
+		return fmt.Sprintf("constraints changed from %s to %s", orNone(c.Old), orNone(c.New))
+	}
+	return string(c.Kind)
+}
+
+// orNone returns s, or "none" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Diff compares the spec and status fields of the documented versions of two XRDs
+func (g *Generator) Diff(old, new *XRD) (DiffResult, error) {
+	return g.DiffVersions(old, "", new, "")
+}
+
+// DiffVersions compares the spec and status fields of the named versions of two
+// XRDs, which may be the same XRD. An empty name selects the documented version.
+func (g *Generator) DiffVersions(old *XRD, oldVersion string, new *XRD, newVersion string) (DiffResult, error) {
+	from, err := diffVersion(old, oldVersion)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	to, err := diffVersion(new, newVersion)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	before := make(map[string]matrixField)
+	g.collectMatrixFields(from.Schema.OpenAPIV3Schema, "", before)
+	after := make(map[string]matrixField)
+	g.collectMatrixFields(to.Schema.OpenAPIV3Schema, "", after)
+
+	result := DiffResult{OldVersion: from.Name, NewVersion: to.Name, Changes: []Change{}}
+	for path, b := range before {
+		if !isDocumentedPath(path) {
+			continue
+		}
+		a, ok := after[path]
+		if !ok {
+			// Fields below a removed field are removed with it
+			if parent := parentPath(path); hasPath(after, parent) || !hasPath(before, parent) {
+				result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeRemoved, Severity: SeverityBreaking})
+			}
+			continue
+		}
+		if a.Type != b.Type {
+			result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeType, Severity: SeverityBreaking, Old: b.Type, New: a.Type})
+		}
+		switch {
+		case a.Required && !b.Required:
+			result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeRequired, Severity: SeverityBreaking})
+		case !a.Required && b.Required:
+			result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeOptional, Severity: SeverityInfo})
+		}
+		if a.Constraints != b.Constraints {
+			result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeConstraints, Severity: SeverityReview, Old: b.Constraints, New: a.Constraints})
+		}
+	}
+	for path, a := range after {
+		if !isDocumentedPath(path) || hasPath(before, path) {
+			continue
+		}
+		// Only a new field's own requiredness matters; its children come with it
+		if parent := parentPath(path); hasPath(after, parent) && !hasPath(before, parent) {
+			continue
+		}
+		severity := SeverityInfo
+		if a.Required {
+			severity = SeverityBreaking
+		}
+		result.Changes = append(result.Changes, Change{Path: path, Kind: ChangeAdded, Severity: severity, New: a.Type})
+	}
+
+	sort.Slice(result.Changes, func(i, j int) bool {
+		if result.Changes[i].Path != result.Changes[j].Path {
+			return result.Changes[i].Path < result.Changes[j].Path
+		}
+		return result.Changes[i].Kind < result.Changes[j].Kind
+	})
+	return result, nil
+}
+
+// diffVersion returns the named version of the XRD, or its documented version
+func diffVersion(xrd *XRD, name string) (*XRDVersion, error) {
+	if len(xrd.Spec.Versions) == 0 {
+		return nil, fmt.Errorf("no versions found in XRD %s", xrd.Metadata.Name)
+	}
+	index, err := xrd.versionIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return &xrd.Spec.Versions[index], nil
+}
+
+// isDocumentedPath reports whether a field path is below spec or status
+func isDocumentedPath(path string) bool {
+	return strings.HasPrefix(path, "spec.") || strings.HasPrefix(path, "status.")
+}
+
+// parentPath returns the path of a field's parent, e.g. spec for spec.region.
+// Fields of list items and map values belong to the list or map field, so
+// spec.subnets[].cidr has the parent spec.subnets.
+func parentPath(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return ""
+	}
+	parent := path[:i]
+	for {
+		trimmed := strings.TrimSuffix(strings.TrimSuffix(parent, "[]"), ".*")
+		if trimmed == parent {
+			return parent
+		}
+		parent = trimmed
+	}
+}
+
+// hasPath reports whether fields has the path
+func hasPath(fields map[string]matrixField, path string) bool {
+	_, ok := fields[path]
+	return ok
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+const subnetsV1 = `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      subnets:
+        type: array
+        items:
+          type: object
+          properties:
+            cidr:
+              type: string
+            zone:
+              type: string
+      tags:
+        type: object
+        additionalProperties:
+          type: object
+          properties:
+            value:
+              type: string
+`
+
+const subnetsV2 = `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      subnets:
+        type: array
+        items:
+          type: object
+          required: [name]
+          properties:
+            name:
+              type: string
+            zone:
+              type: integer
+      tags:
+        type: object
+        additionalProperties:
+          type: object
+          properties:
+            value:
+              type: integer
+`
+
+func TestDiffListAndMapFields(t *testing.T) {
+	old := testXRD(testVersion(t, "v1", subnetsV1))
+	new := testXRD(testVersion(t, "v1", subnetsV2))
+
+	result, err := New().Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range result.Changes {
+		got = append(got, c.Path+" "+string(c.Kind)+" "+string(c.Severity))
+	}
+	want := []string{
+		"spec.subnets[].cidr removed breaking",
+		"spec.subnets[].name added breaking",
+		"spec.subnets[].zone type breaking",
+		"spec.tags.*.value type breaking",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes:\n got %q\nwant %q", got, want)
+	}
+	if !result.Breaking() {
+		t.Error("Breaking() = false, want true")
+	}
+}
+
+func TestDiffRemovedListReportsOnlyTheList(t *testing.T) {
+	old := testXRD(testVersion(t, "v1", subnetsV1))
+	new := testXRD(testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      tags:
+        type: object
+        additionalProperties:
+          type: object
+          properties:
+            value:
+              type: string
+`))
+
+	result, err := New().Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Path != "spec.subnets" || result.Changes[0].Kind != ChangeRemoved {
+		t.Errorf("changes = %+v, want only spec.subnets removed", result.Changes)
+	}
+}
+
+func TestParentPath(t *testing.T) {
+	tests := map[string]string{
+		"spec":                  "",
+		"spec.region":           "spec",
+		"spec.subnets[].cidr":   "spec.subnets",
+		"spec.matrix[][].value": "spec.matrix",
+		"spec.tags.*.value":     "spec.tags",
+	}
+	for path, want := range tests {
+		if got := parentPath(path); got != want {
+			t.Errorf("parentPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
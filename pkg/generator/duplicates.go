@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DuplicateKey describes a mapping key that is declared more than once
+type DuplicateKey struct {
+	Path      string // Path of the mapping containing the key
+	Key       string
+	Line      int // Line of the duplicate declaration
+	FirstLine int // Line of the first declaration
+}
+
+func (d DuplicateKey) Error() string {
+	return fmt.Sprintf("%s: duplicate key %q at line %d (first declared at line %d)", d.Path, d.Key, d.Line, d.FirstLine)
+}
+
+// DetectDuplicateKeys reports every mapping key declared more than once in the YAML data,
+// including within the OpenAPI schema properties
+func DetectDuplicateKeys(data []byte) ([]DuplicateKey, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	var dupes []DuplicateKey
+	walkDuplicates(&root, "", &dupes)
+	return dupes, nil
+}
+
+// walkDuplicates recursively collects duplicate mapping keys below node
+func walkDuplicates(node *yaml.Node, path string, dupes *[]DuplicateKey) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkDuplicates(child, path, dupes)
+		}
+	case yaml.MappingNode:
+		seen := make(map[string]int)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if first, ok := seen[key.Value]; ok {
+				*dupes = append(*dupes, DuplicateKey{
+					Path:      displayPath(path),
+					Key:       key.Value,
+					Line:      key.Line,
+					FirstLine: first,
+				})
+			} else {
+				seen[key.Value] = key.Line
+			}
+			walkDuplicates(value, joinPath(path, key.Value), dupes)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkDuplicates(child, path+"["+strconv.Itoa(i)+"]", dupes)
+		}
+	}
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
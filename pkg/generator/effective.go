@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompositionDefault is a value a composition supplies for an XR field left unset
+type CompositionDefault struct {
+	Value       string
+	Composition string
+}
+
+// effectiveDefault describes the value a field ends up with when a minimal XR
+// leaves it unset, labelled with the layer that supplies it. The XRD default is
+// applied by the API server before composition runs, so it always wins.
+func effectiveDefault(field Field, defaults []CompositionDefault) string {
+	if field.Default != "" {
+		return fmt.Sprintf("`%s` (XRD)", field.Default)
+	}
+	if field.Required {
+		// A minimal XR must set it, so a composition value never applies
+		return ""
+	}
+
+	// Group compositions by the value they supply, keeping first-seen order
+	var values []string
+	sources := make(map[string][]string)
+	for _, d := range defaults {
+		if _, ok := sources[d.Value]; !ok {
+			values = append(values, d.Value)
+		}
+		if !contains(sources[d.Value], d.Composition) {
+			sources[d.Value] = append(sources[d.Value], d.Composition)
+		}
+	}
+
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("`%s` (composition %s)", v, codeList(sources[v])))
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return "varies: " + strings.Join(parts, ", ")
+	}
+}
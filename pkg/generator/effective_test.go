@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEffectiveDefaultNote(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      size: {type: string}
+`))
+	bundled := map[string][]CompositionDefault{
+		"spec.size": {{Value: "small", Composition: "aws"}},
+	}
+	note := "Only base values are considered"
+
+	for _, opts := range []Options{
+		{CompositionDefaults: bundled},
+		{CompositionDefaults: bundled, TableFormat: "wide"},
+		{CompositionDefaults: bundled, Format: "html"},
+	} {
+		doc, err := New().Generate(xrd, opts)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if !strings.Contains(doc, "`small` (composition `aws`)") {
+			t.Errorf("%+v: output has no effective default:\n%s", opts, doc)
+		}
+		if !strings.Contains(doc, note) {
+			t.Errorf("%+v: output doesn't say only base values are considered:\n%s", opts, doc)
+		}
+	}
+
+	doc, err := New().Generate(xrd, Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(doc, note) {
+		t.Errorf("output without bundled compositions has the effective default note:\n%s", doc)
+	}
+}
@@ -0,0 +1,501 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// buildExample builds an example manifest for the version, filling spec fields
+// with their default, schema example, or a type-appropriate placeholder. The
+// required mode only fills required fields; full fills every field.
+func (g *Generator) buildExample(xrd *XRD, version *XRDVersion, mode string) (string, error) {
+	full := mode == "full"
+	return g.buildManifest(xrd, version, func(schema OpenAPISchema) (*yaml.Node, error) {
+		return g.exampleNode(schema, full)
+	})
+}
+
+// buildQuickstart builds the minimal manifest for the version: required spec
+// fields filled with their default, or a placeholder commented as to be replaced
+func (g *Generator) buildQuickstart(xrd *XRD, version *XRDVersion) (string, error) {
+	return g.buildManifest(xrd, version, func(schema OpenAPISchema) (*yaml.Node, error) {
+		return g.quickstartNode("", schema)
+	})
+}
+
+// buildManifest renders a manifest for the version with the spec built by specNode
+func (g *Generator) buildManifest(xrd *XRD, version *XRDVersion, specNode func(OpenAPISchema) (*yaml.Node, error)) (string, error) {
+	target := xrd.ManifestTarget()
+
+	spec := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	specSchema, hasSpec := version.Schema.OpenAPIV3Schema.Properties["spec"]
+	if hasSpec {
+		node, err := specNode(specSchema)
+		if err != nil {
+			return "", err
+		}
+		if node.Kind == yaml.MappingNode && len(node.Content) > 0 {
+			spec = node
+		}
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	addExampleEntry(doc, "apiVersion", scalarNode(xrd.Spec.Group+"/"+version.Name))
+	addExampleEntry(doc, "kind", scalarNode(target.Kind))
+	metadata := &yaml.Node{Kind: yaml.MappingNode}
+	addExampleEntry(metadata, "name", scalarNode("example"))
+	if target.Namespaced {
+		addExampleEntry(metadata, "namespace", scalarNode("default"))
+	}
+	addExampleEntry(doc, "metadata", metadata)
+	// Plain CRDs may have no spec at all
+	if hasSpec || !xrd.IsCRD() {
+		addExampleEntry(doc, "spec", spec)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("failed to render example: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to render example: %w", err)
+	}
+
+	// Make sure the manifest reads back as YAML before it's documented
+	var manifest map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &manifest); err != nil {
+		return "", fmt.Errorf("generated example is not valid YAML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// quickstartNode builds the quick start value for a schema. Values the user has
+// to choose are placeholders with a "replace" comment so they stand out.
+func (g *Generator) quickstartNode(name string, schema OpenAPISchema) (*yaml.Node, error) {
+	if schema.Default != nil {
+		return valueNode(schema.Default)
+	}
+
+	var (
+		node *yaml.Node
+		err  error
+	)
+	switch {
+	case len(schema.Enum) > 0:
+		node, err = valueNode(schema.Enum[0])
+		if err == nil {
+			node.LineComment = "replace: one of " + formatEnum(schema.Enum)
+		}
+		return node, err
+	case schema.Type == "object":
+		node = &yaml.Node{Kind: yaml.MappingNode}
+		for _, prop := range schema.Required {
+			propSchema, ok := schema.Properties[prop]
+			if !ok {
+				continue
+			}
+			child, err := g.quickstartNode(prop, propSchema)
+			if err != nil {
+				return nil, err
+			}
+			addExampleEntry(node, prop, child)
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case schema.Type == "array":
+		node = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		if schema.Items != nil && schema.MinItems != nil && *schema.MinItems > 0 {
+			node.Style = 0
+			for i := 0; i < *schema.MinItems; i++ {
+				item, err := g.quickstartNode(name, *schema.Items)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+			return node, nil
+		}
+		node.LineComment = "replace: add items"
+		return node, nil
+	case schema.Type == "integer" || schema.Type == "number":
+		node, err = g.exampleNode(schema, false)
+	case schema.Type == "boolean":
+		node, err = valueNode(false)
+	default:
+		node, err = valueNode("<" + name + ">")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node.LineComment = "replace"
+	if schema.Description != "" {
+		node.LineComment += ": " + firstLine(schema.Description)
+	}
+	return node, nil
+}
+
+// exampleNode builds the example value for a schema, descending into every
+// property and giving arrays one item when full is set
+func (g *Generator) exampleNode(schema OpenAPISchema, full bool) (*yaml.Node, error) {
+	if schema.Default != nil {
+		return valueNode(schema.Default)
+	}
+	if schema.Example != nil {
+		return valueNode(schema.Example)
+	}
+	if len(schema.Enum) > 0 {
+		return valueNode(schema.Enum[0])
+	}
+
+	switch schema.Type {
+	case "object":
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, name := range exampleProperties(schema, full) {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				continue
+			}
+			child, err := g.exampleNode(prop, full)
+			if err != nil {
+				return nil, err
+			}
+			addExampleEntry(node, name, child)
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case "array":
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if schema.Items != nil {
+			count := 0
+			if schema.MinItems != nil {
+				count = *schema.MinItems
+			}
+			if full && count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				item, err := g.exampleNode(*schema.Items, full)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case "integer", "number":
+		return valueNode(exampleNumber(schema))
+	case "boolean":
+		return valueNode(true)
+	default:
+		value, ok := exampleString(schema)
+		node, err := valueNode(value)
+		if err == nil && !ok {
+			node.LineComment = "replace: must match " + *schema.Pattern
+		}
+		return node, err
+	}
+}
+
+// exampleStringCandidates are the placeholders tried, in order, for a string field
+var exampleStringCandidates = []string{"string", "example", "example-1", "example.org", "a", "A", "1"}
+
+// exampleString returns the example value of a string field: the first candidate
+// that fits its length bounds and pattern. When no candidate matches the pattern
+// the placeholder is returned with ok unset, since the user has to provide a value.
+func exampleString(schema OpenAPISchema) (value string, ok bool) {
+	pattern := compilePattern(schema.Pattern)
+	for _, candidate := range exampleStringCandidates {
+		candidate = fitLength(candidate, schema)
+		if pattern == nil || pattern.MatchString(candidate) {
+			return candidate, true
+		}
+	}
+	return fitLength(exampleStringCandidates[0], schema), false
+}
+
+// fitLength pads or truncates s to the minLength and maxLength of the schema
+func fitLength(s string, schema OpenAPISchema) string {
+	if schema.MinLength != nil && len(s) < *schema.MinLength {
+		s += strings.Repeat("x", *schema.MinLength-len(s))
+	}
+	if schema.MaxLength != nil && len(s) > *schema.MaxLength {
+		s = s[:*schema.MaxLength]
+	}
+	return s
+}
+
+// compilePattern compiles a schema pattern. Patterns are ECMA-262 regular
+// expressions; the ones Go can't compile aren't checked.
+func compilePattern(pattern *string) *regexp.Regexp {
+	if pattern == nil {
+		return nil
+	}
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// exampleNumber returns the example value of a numeric field: zero or its minimum,
+// moved inside the bounds when they exclude it
+func exampleNumber(schema OpenAPISchema) interface{} {
+	integer := schema.Type == "integer"
+
+	value := 0.0
+	if schema.Minimum != nil {
+		value = *schema.Minimum
+		switch {
+		case schema.ExclusiveMinimum && integer:
+			value = math.Floor(value) + 1
+		case schema.ExclusiveMinimum && schema.Maximum != nil:
+			value = (value + *schema.Maximum) / 2
+		case schema.ExclusiveMinimum:
+			value++
+		case integer:
+			value = math.Ceil(value)
+		}
+	}
+	if schema.Maximum != nil && (value > *schema.Maximum || schema.ExclusiveMaximum && value == *schema.Maximum) {
+		value = *schema.Maximum
+		switch {
+		case schema.ExclusiveMaximum && integer:
+			value = math.Ceil(value) - 1
+		case schema.ExclusiveMaximum && schema.Minimum != nil:
+			value = (value + *schema.Minimum) / 2
+		case schema.ExclusiveMaximum:
+			value--
+		case integer:
+			value = math.Floor(value)
+		}
+	}
+
+	if integer {
+		return int64(value)
+	}
+	return value
+}
+
+// exampleProperties returns the properties an example sets: the required ones in
+// declaration order, followed by the optional ones alphabetically when full is set
+func exampleProperties(schema OpenAPISchema, full bool) []string {
+	names := append([]string(nil), schema.Required...)
+	if !full {
+		return names
+	}
+
+	var optional []string
+	for name := range schema.Properties {
+		if !contains(schema.Required, name) {
+			optional = append(optional, name)
+		}
+	}
+	sort.Strings(optional)
+	return append(names, optional...)
+}
+
+// formatEnum lists enum values for a YAML comment
+func formatEnum(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// firstLine returns the first line of a description
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+// valueNode encodes an arbitrary value as a YAML node
+func valueNode(v interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode example value: %w", err)
+	}
+	return &node, nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+func addExampleEntry(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, scalarNode(key), value)
+}
+
+// validateExample checks that the spec of the example manifest conforms to the version schema
+func (g *Generator) validateExample(example string, version *XRDVersion) error {
+	var manifest map[string]interface{}
+	if err := yaml.Unmarshal([]byte(example), &manifest); err != nil {
+		return fmt.Errorf("generated example is not valid YAML: %w", err)
+	}
+
+	specSchema, ok := version.Schema.OpenAPIV3Schema.Properties["spec"]
+	if !ok {
+		return nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(example), &doc); err != nil {
+		return fmt.Errorf("generated example is not valid YAML: %w", err)
+	}
+	placeholders := map[string]bool{}
+	if len(doc.Content) > 0 {
+		collectPlaceholders(doc.Content[0], "", placeholders)
+	}
+
+	errs := validateValue(manifest["spec"], specSchema, "spec", placeholders)
+	if len(errs) > 0 {
+		// Object properties are visited in map order
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return fmt.Errorf("generated example does not conform to the schema:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// collectPlaceholders records the paths of the values commented as to be replaced
+func collectPlaceholders(node *yaml.Node, path string, paths map[string]bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			collectPlaceholders(node.Content[i+1], joinPath(path, node.Content[i].Value), paths)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			collectPlaceholders(item, fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case yaml.ScalarNode:
+		if strings.HasPrefix(node.LineComment, "# replace") {
+			paths[path] = true
+		}
+	}
+}
+
+// validateValue validates a decoded YAML value against a schema. The pattern of
+// the placeholder paths isn't checked, they're flagged for the user to fill in.
+func validateValue(value interface{}, schema OpenAPISchema, path string, placeholders map[string]bool) []error {
+	var errs []error
+
+	if value == nil {
+		return errs
+	}
+
+	switch schema.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected object, got %T", path, value))
+		}
+		for _, name := range schema.Required {
+			if _, ok := m[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: required field %q is missing", path, name))
+			}
+		}
+		for name, v := range m {
+			if prop, ok := schema.Properties[name]; ok {
+				errs = append(errs, validateValue(v, prop, joinPath(path, name), placeholders)...)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected array, got %T", path, value))
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			errs = append(errs, fmt.Errorf("%s: has %d item(s), minItems is %d", path, len(items), *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			errs = append(errs, fmt.Errorf("%s: has %d item(s), maxItems is %d", path, len(items), *schema.MaxItems))
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				errs = append(errs, validateValue(item, *schema.Items, fmt.Sprintf("%s[%d]", path, i), placeholders)...)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected string, got %T", path, value))
+		}
+		if schema.MinLength != nil && utf8.RuneCountInString(s) < *schema.MinLength {
+			errs = append(errs, fmt.Errorf("%s: %q is shorter than minLength %d", path, s, *schema.MinLength))
+		}
+		if schema.MaxLength != nil && utf8.RuneCountInString(s) > *schema.MaxLength {
+			errs = append(errs, fmt.Errorf("%s: %q is longer than maxLength %d", path, s, *schema.MaxLength))
+		}
+		if re := compilePattern(schema.Pattern); re != nil && !re.MatchString(s) && !placeholders[path] {
+			errs = append(errs, fmt.Errorf("%s: %q doesn't match the pattern %s", path, s, *schema.Pattern))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Errorf("%s: expected boolean, got %T", path, value))
+		}
+	case "integer", "number":
+		n, ok := toFloat(value)
+		if !ok || (schema.Type == "integer" && n != float64(int64(n))) {
+			return append(errs, fmt.Errorf("%s: expected %s, got %v", path, schema.Type, value))
+		}
+		if schema.Minimum != nil && (n < *schema.Minimum || schema.ExclusiveMinimum && n == *schema.Minimum) {
+			errs = append(errs, fmt.Errorf("%s: %v is below the minimum %v", path, value, *schema.Minimum))
+		}
+		if schema.Maximum != nil && (n > *schema.Maximum || schema.ExclusiveMaximum && n == *schema.Maximum) {
+			errs = append(errs, fmt.Errorf("%s: %v is above the maximum %v", path, value, *schema.Maximum))
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		errs = append(errs, fmt.Errorf("%s: %v is not one of the allowed values", path, value))
+	}
+
+	return errs
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+		if a, ok := toFloat(e); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
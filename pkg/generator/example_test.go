@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExampleNumberStaysWithinBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   interface{}
+	}{
+		{"unbounded", "type: integer", int64(0)},
+		{"minimum", "{type: integer, minimum: 3}", int64(3)},
+		{"exclusive minimum", "{type: integer, minimum: 3, exclusiveMinimum: true}", int64(4)},
+		{"negative maximum", "{type: integer, maximum: -5}", int64(-5)},
+		{"exclusive negative maximum", "{type: integer, maximum: -5, exclusiveMaximum: true}", int64(-6)},
+		{"fractional maximum", "{type: integer, maximum: -0.5}", int64(-1)},
+		{"negative number maximum", "{type: number, maximum: -2.5}", -2.5},
+		{"exclusive number bounds", "{type: number, minimum: -3, maximum: -1, exclusiveMaximum: true}", -3.0},
+		{"exclusive number range", "{type: number, minimum: 1, maximum: 2, exclusiveMinimum: true}", 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema(t, tt.schema)
+			got := exampleNumber(schema)
+			if got != tt.want {
+				t.Errorf("exampleNumber() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+			if errs := validateValue(got, schema, "spec.n", nil); len(errs) != 0 {
+				t.Errorf("example %v doesn't validate: %v", got, errs)
+			}
+		})
+	}
+}
+
+func TestValidateExampleWithNegativeMaximum(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    required: [offset]
+    properties:
+      offset:
+        type: integer
+        maximum: -5
+`))
+
+	if _, err := New().Generate(xrd, Options{ValidateExample: true}); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+}
+
+func TestExampleStringFitsConstraints(t *testing.T) {
+	tests := []struct {
+		name       string
+		schema     string
+		want       string
+		needsValue bool
+	}{
+		{"plain", "type: string", "string", false},
+		{"minLength", "{type: string, minLength: 8}", "stringxx", false},
+		{"maxLength", "{type: string, maxLength: 3}", "str", false},
+		{"pattern", "{type: string, pattern: '^[a-z]+-[0-9]+$'}", "example-1", false},
+		{"pattern and length", "{type: string, pattern: '^[a-z]+$', minLength: 10}", "stringxxxx", false},
+		{"unmatched pattern", "{type: string, pattern: '^arn:aws:'}", "string", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema(t, tt.schema)
+			got, ok := exampleString(schema)
+			if got != tt.want || ok == tt.needsValue {
+				t.Errorf("exampleString() = %q, %v, want %q, %v", got, ok, tt.want, !tt.needsValue)
+			}
+		})
+	}
+}
+
+func TestValidateValueStringConstraints(t *testing.T) {
+	schema := testSchema(t, "{type: string, pattern: '^[a-z]+$', minLength: 2, maxLength: 4}")
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"abc", ""},
+		{"a", "shorter than minLength 2"},
+		{"abcde", "longer than maxLength 4"},
+		{"AB", "doesn't match the pattern"},
+	}
+	for _, tt := range tests {
+		errs := validateValue(tt.value, schema, "spec.name", nil)
+		switch {
+		case tt.want == "" && len(errs) != 0:
+			t.Errorf("validateValue(%q) = %v, want no errors", tt.value, errs)
+		case tt.want != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want)):
+			t.Errorf("validateValue(%q) = %v, want %q", tt.value, errs, tt.want)
+		}
+	}
+}
+
+const stringConstraintsSchema = `
+type: object
+properties:
+  spec:
+    type: object
+    required: [name, arn]
+    properties:
+      name:
+        type: string
+        pattern: '^[a-z]+-[0-9]+$'
+        maxLength: 12
+      arn:
+        type: string
+        pattern: '^arn:aws:'
+`
+
+func TestGenerateExampleHonoursStringConstraints(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", stringConstraintsSchema))
+
+	doc, err := New().Generate(xrd, Options{ValidateExample: true})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{"name: example-1", "arn: string # replace: must match ^arn:aws:"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("example doesn't contain %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestValidateExampleRejectsPlaceholderOutsideComment(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", stringConstraintsSchema))
+	example := "apiVersion: example.org/v1\nkind: XTest\nspec:\n  name: example-1\n  arn: string\n"
+
+	err := New().validateExample(example, &xrd.Spec.Versions[0])
+	if err == nil || !strings.Contains(err.Error(), "spec.arn") {
+		t.Errorf("validateExample() = %v, want a pattern error on spec.arn", err)
+	}
+}
+
+func TestValidateExampleReportsEveryViolation(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", stringConstraintsSchema))
+	example := "apiVersion: example.org/v1\nkind: XTest\nspec:\n  name: not-a-valid-name\n"
+
+	err := New().validateExample(example, &xrd.Spec.Versions[0])
+	if err == nil {
+		t.Fatal("validateExample() = nil, want errors")
+	}
+	for _, want := range []string{`required field "arn" is missing`, "spec.name"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("validateExample() = %v, want it to report %s", err, want)
+		}
+	}
+}
@@ -1,22 +1,117 @@
+// Package generator documents Crossplane XRDs and plain CRDs in three stages
+// that can be used on their own: Parse reads the definition, ExtractFields
+// returns its structured spec and status fields, and Generate renders them.
 package generator
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
+	"github.com/michielvha/crossplane-docs/pkg/section"
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Options contains generation options
 type Options struct {
-	ShowNested bool // show nested object structures
+	Format  string // output format: markdown (default), html, csv or json
+	Flavor  string // markdown flavor: github (default) or techdocs (MkDocs)
+	Title   string // H1 heading, defaults to the Kind
+	Version string // version to document, defaults to the referenceable (storage) version
+
+	TemplateFile string // text/template file used instead of the built-in layout
+
+	Audience  string // platform (default) or consumer for a concise, claim-user view
+	Only      string // render only this section, e.g. spec, status or example
+	NoHeading bool   // drop the heading of the section rendered with Only
+
+	ShowNested   bool   // show nested object structures
+	TableFormat  string // spec table columns: table (default), compact or wide
+	IndentStyle  string // nested field marker: arrows (default), dots or plain
+	FullPaths    bool   // show each field's full dotted path instead of an indented name
+	TOC          bool   // add a linked table of contents below the title
+	SortMode     string // field order: required-first (default), alphabetical or schema
+	ShowRemoved  bool   // list fields removed since earlier served versions
+	ShowMatrix   bool   // add a matrix comparing fields across served versions
+	AllVersions  bool   // document every version in its own section instead of only the storage version
+	ShowExamples bool   // add an Example column with per-field schema examples
+	FieldAnchors bool   // add an anchor per field and link field references in descriptions
+	RequiredOnly bool   // only document required spec fields
+
+	ExcludeTypes []string // drop fields of these types from the tables, e.g. object
+
+	ValidateExample bool // fail if the generated example doesn't conform to the schema
+	Quickstart      bool // add a minimal manifest with only the required fields
+
+	HideMetadata bool // omit the API group/version/kind block
+	HideStatus   bool // omit the status fields section
+	HideExample  bool // omit the example section
+
+	ExampleMode string // fields set in the example: required (default), full, or none to omit it
+
+	MetadataPrefixes []string // show the XRD's labels and annotations whose keys start with one of these
+
+	StatusDescribedOnly   bool     // only document status fields that have a description
+	StatusInclude         []string // only document status fields matching these globs
+	IncludeStandardStatus bool     // add the status fields Crossplane adds, such as conditions
+
+	QuantityFields []string // field name keywords treated as Kubernetes quantities, e.g. memory
+
+	// CompositionDefaults holds the values compositions supply for unset fields, keyed
+	// by field path. When non-nil (bundle mode) an Effective Default column is added.
+	CompositionDefaults map[string][]CompositionDefault
+
+	// Compositions summarizes the bundled compositions for the consumer audience
+	Compositions []BundledComposition
+}
+
+// OptionError reports options that don't apply to the output format or can't be
+// used together. Options names the Options fields, optionally with the offending
+// value, e.g. Audience=consumer, so front ends can report their own flag names.
+type OptionError struct {
+	Options []string
+	Reason  string // follows the list of options, e.g. "can't be combined"
+}
+
+func (e *OptionError) Error() string {
+	return e.Message(func(option string) string { return option })
 }
 
+// Message renders the error with each option spelt by name, e.g. as a CLI flag
+func (e *OptionError) Message(name func(option string) string) string {
+	names := make([]string, len(e.Options))
+	for i, option := range e.Options {
+		field, value, hasValue := strings.Cut(option, "=")
+		names[i] = name(field)
+		if hasValue {
+			names[i] += "=" + value
+		}
+	}
+	list := strings.Join(names, " and ")
+	if len(names) > 2 {
+		list = strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+	return list + " " + e.Reason
+}
+
+// markdownOnly reports the options that only apply to markdown output
+func markdownOnly(options ...string) error {
+	return &OptionError{Options: options, Reason: "can only be used with markdown output"}
+}
+
+// DefaultQuantityFields are the field name keywords treated as Kubernetes quantities by default
+var DefaultQuantityFields = []string{"cpu", "memory", "storage"}
+
 // Generator handles documentation generation
 type Generator struct{}
 
@@ -35,17 +130,22 @@ type XRD struct {
 
 // XRDSpec contains the XRD specification
 type XRDSpec struct {
-	Group      string       `yaml:"group"`
-	Names      XRDNames     `yaml:"names"`
-	ClaimNames *XRDNames    `yaml:"claimNames,omitempty"`
-	Versions   []XRDVersion `yaml:"versions"`
+	Group      string    `yaml:"group"`
+	Names      XRDNames  `yaml:"names"`
+	ClaimNames *XRDNames `yaml:"claimNames,omitempty"`
+	Scope      string    `yaml:"scope,omitempty"` // Crossplane v2: Namespaced, Cluster or LegacyCluster
+
+	ConnectionSecretKeys []string     `yaml:"connectionSecretKeys,omitempty"`
+	Versions             []XRDVersion `yaml:"versions"`
 }
 
 // XRDNames contains the resource names
 type XRDNames struct {
-	Kind     string `yaml:"kind"`
-	Plural   string `yaml:"plural"`
-	Singular string `yaml:"singular,omitempty"`
+	Kind       string   `yaml:"kind"`
+	Plural     string   `yaml:"plural"`
+	Singular   string   `yaml:"singular,omitempty"`
+	ShortNames []string `yaml:"shortNames,omitempty"`
+	Categories []string `yaml:"categories,omitempty"`
 }
 
 // XRDVersion represents a version in the XRD
@@ -53,10 +153,36 @@ type XRDVersion struct {
 	Name                     string                   `yaml:"name"`
 	Served                   bool                     `yaml:"served"`
 	Referenceable            bool                     `yaml:"referenceable"`
+	Storage                  bool                     `yaml:"storage,omitempty"` // CRDs mark the storage version instead
 	Schema                   XRDVersionSchema         `yaml:"schema"`
 	AdditionalPrinterColumns []map[string]interface{} `yaml:"additionalPrinterColumns,omitempty"`
 }
 
+// PrinterColumn is an additional column kubectl get prints for the resource
+type PrinterColumn struct {
+	Name        string
+	Type        string
+	JSONPath    string
+	Description string
+}
+
+// printerColumns returns the version's additional printer columns, skipping
+// entries without a name or JSON path
+func (v *XRDVersion) printerColumns() []PrinterColumn {
+	var columns []PrinterColumn
+	for _, c := range v.AdditionalPrinterColumns {
+		name, _ := c["name"].(string)
+		jsonPath, _ := c["jsonPath"].(string)
+		if name == "" || jsonPath == "" {
+			continue
+		}
+		colType, _ := c["type"].(string)
+		description, _ := c["description"].(string)
+		columns = append(columns, PrinterColumn{Name: name, Type: colType, JSONPath: jsonPath, Description: description})
+	}
+	return columns
+}
+
 // XRDVersionSchema contains the OpenAPI schema
 type XRDVersionSchema struct {
 	OpenAPIV3Schema OpenAPISchema `yaml:"openAPIV3Schema"`
@@ -64,77 +190,526 @@ type XRDVersionSchema struct {
 
 // OpenAPISchema represents the OpenAPI v3 schema
 type OpenAPISchema struct {
-	Type                   string                   `yaml:"type"`
-	Description            string                   `yaml:"description,omitempty"`
-	Properties             map[string]OpenAPISchema `yaml:"properties,omitempty"`
-	Items                  *OpenAPISchema           `yaml:"items,omitempty"`
-	Required               []string                 `yaml:"required,omitempty"`
-	Default                interface{}              `yaml:"default,omitempty"`
-	Enum                   []interface{}            `yaml:"enum,omitempty"`
-	Minimum                *float64                 `yaml:"minimum,omitempty"`
-	Maximum                *float64                 `yaml:"maximum,omitempty"`
-	MinItems               *int                     `yaml:"minItems,omitempty"`
-	MaxItems               *int                     `yaml:"maxItems,omitempty"`
-	XKubernetesValidations []map[string]interface{} `yaml:"x-kubernetes-validations,omitempty"`
+	Type                             string                   `yaml:"type"`
+	Description                      string                   `yaml:"description,omitempty"`
+	Properties                       map[string]OpenAPISchema `yaml:"properties,omitempty"`
+	Items                            *OpenAPISchema           `yaml:"items,omitempty"`
+	AdditionalProperties             *OpenAPISchema           `yaml:"additionalProperties,omitempty"`
+	Required                         []string                 `yaml:"required,omitempty"`
+	Default                          interface{}              `yaml:"default,omitempty"`
+	Example                          interface{}              `yaml:"example,omitempty"`
+	Enum                             []interface{}            `yaml:"enum,omitempty"`
+	Deprecated                       bool                     `yaml:"deprecated,omitempty"`
+	Minimum                          *float64                 `yaml:"minimum,omitempty"`
+	Maximum                          *float64                 `yaml:"maximum,omitempty"`
+	ExclusiveMinimum                 bool                     `yaml:"exclusiveMinimum,omitempty"` // minimum itself isn't allowed
+	ExclusiveMaximum                 bool                     `yaml:"exclusiveMaximum,omitempty"` // maximum itself isn't allowed
+	MinItems                         *int                     `yaml:"minItems,omitempty"`
+	MaxItems                         *int                     `yaml:"maxItems,omitempty"`
+	Format                           string                   `yaml:"format,omitempty"`
+	Pattern                          *string                  `yaml:"pattern,omitempty"`
+	MinLength                        *int                     `yaml:"minLength,omitempty"`
+	MaxLength                        *int                     `yaml:"maxLength,omitempty"`
+	OneOf                            []OpenAPISchema          `yaml:"oneOf,omitempty"`
+	AnyOf                            []OpenAPISchema          `yaml:"anyOf,omitempty"`
+	AllOf                            []OpenAPISchema          `yaml:"allOf,omitempty"`
+	XKubernetesValidations           []map[string]interface{} `yaml:"x-kubernetes-validations,omitempty"`
+	XKubernetesIntOrString           bool                     `yaml:"x-kubernetes-int-or-string,omitempty"`
+	XKubernetesPreserveUnknownFields *bool                    `yaml:"x-kubernetes-preserve-unknown-fields,omitempty"`
+
+	denied        bool     // set for the boolean schema false, e.g. additionalProperties: false
+	propertyOrder []string // property names in the order they are declared
+}
+
+// UnmarshalYAML decodes a schema, also accepting the boolean schemas true (anything)
+// and false (nothing) that OpenAPI allows for additionalProperties
+func (s *OpenAPISchema) UnmarshalYAML(node *yaml.Node) error {
+	// A schema reused through an alias, e.g. items: *subnet, arrives as the alias
+	node = resolveAlias(node)
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		var allowed bool
+		if err := node.Decode(&allowed); err != nil {
+			return err
+		}
+		*s = OpenAPISchema{denied: !allowed}
+		return nil
+	}
+
+	type plain OpenAPISchema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+
+	// Properties decode into a map, so their declared order is read from the node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "properties" {
+			s.propertyOrder = mappingKeys(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias refers to, or the node itself
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// mappingKeys returns the keys of a mapping node in declaration order. Keys
+// merged in with <<, from one mapping or a list of them, take the place of the
+// merge key; a key declared more than once is listed once.
+func mappingKeys(node *yaml.Node) []string {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], resolveAlias(node.Content[i+1])
+		if key.Tag != "!!merge" {
+			add(key.Value)
+			continue
+		}
+		merged := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			merged = value.Content
+		}
+		for _, m := range merged {
+			for _, k := range mappingKeys(m) {
+				add(k)
+			}
+		}
+	}
+	return keys
+}
+
+// mapValues returns the schema of map values if the schema describes a map
+func (s OpenAPISchema) mapValues() (*OpenAPISchema, bool) {
+	if s.AdditionalProperties == nil || s.AdditionalProperties.denied {
+		return nil, false
+	}
+	return s.AdditionalProperties, true
+}
+
+// freeForm reports whether the schema accepts arbitrary content below it
+func (s OpenAPISchema) freeForm() bool {
+	return s.XKubernetesPreserveUnknownFields != nil && *s.XKubernetesPreserveUnknownFields
+}
+
+// deprecated reports whether the schema is marked deprecated, with the deprecated
+// key or by the convention of a description starting with "Deprecated"
+func (s OpenAPISchema) deprecated() bool {
+	return s.Deprecated || strings.HasPrefix(strings.ToLower(strings.TrimSpace(s.Description)), "deprecated")
 }
 
 // Field represents a documented field
 type Field struct {
 	Name        string
+	Path        string // Dotted path from the schema root, e.g. spec.parameters.region
 	Type        string
 	Description string
 	Required    bool
+	Deprecated  bool // Marked deprecated in the schema or its description
 	Default     string
+	Example     string
 	Constraints string
+	Effective   string  // Effective default in bundle mode, labelled with its layer
 	Nested      []Field // For nested object fields
 	Level       int     // Nesting level for display
+	Anchor      string  // Anchor id when field anchors are enabled
+
+	schema OpenAPISchema // Schema the field was extracted from, for typed output
 }
 
-// GenerateFromFile generates documentation from an XRD file
+// RemovedFields lists the fields of an earlier version that are absent in the documented one
+type RemovedFields struct {
+	Since string
+	Paths []string
+}
+
+// GenerateFromFile generates documentation from an XRD or CRD file
 func (g *Generator) GenerateFromFile(filename string, opts Options) (string, error) {
-	data, err := os.ReadFile(filename)
+	f, err := os.Open(filename)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
+	defer f.Close()
+
+	return g.GenerateFromReader(f, opts)
+}
+
+// GenerateFromReader generates documentation from XRD or CRD YAML read from r
+func (g *Generator) GenerateFromReader(r io.Reader, opts Options) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	xrd, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+	if xrd.Kind != "" && !IsDefinition(xrd.Kind) {
+		return "", fmt.Errorf("can't document a %s: expected a %s or %s", xrd.Kind, KindXRD, KindCRD)
+	}
+
+	return g.Generate(xrd, opts)
+}
 
+// Parse parses XRD or CRD YAML
+func Parse(data []byte) (*XRD, error) {
 	var xrd XRD
 	if err := yaml.Unmarshal(data, &xrd); err != nil {
-		return "", fmt.Errorf("failed to parse XRD YAML: %w", err)
+		return nil, fmt.Errorf("failed to parse XRD YAML: %w", err)
+	}
+	return &xrd, nil
+}
+
+// Kinds of the definitions the generator documents. Plain CRDs share the
+// XRD's names, versions and OpenAPI v3 schema layout.
+const (
+	KindXRD = "CompositeResourceDefinition"
+	KindCRD = "CustomResourceDefinition"
+)
+
+// IsDefinition reports whether a manifest kind can be documented
+func IsDefinition(kind string) bool {
+	return kind == KindXRD || kind == KindCRD
+}
+
+// IsCRD reports whether the definition is a plain CustomResourceDefinition
+func (x *XRD) IsCRD() bool {
+	return x.Kind == KindCRD
+}
+
+// DefaultVersion returns the version documented by default, or nil if the XRD has no versions
+func (x *XRD) DefaultVersion() *XRDVersion {
+	if len(x.Spec.Versions) == 0 {
+		return nil
+	}
+	return &x.Spec.Versions[x.defaultVersionIndex()]
+}
+
+// EffectiveScope returns the scope of the composite resource. Crossplane v2 XRDs
+// default to Namespaced; classic XRDs have cluster-scoped composites and return "".
+func (x *XRD) EffectiveScope() string {
+	if x.Spec.Scope != "" {
+		return x.Spec.Scope
 	}
+	if strings.HasSuffix(x.APIVersion, "/v2") {
+		return "Namespaced"
+	}
+	return ""
+}
 
-	return g.Generate(&xrd, opts)
+// OffersClaims reports whether users interact with the XRD through claims.
+// Crossplane v2 XRDs only support claims in the LegacyCluster scope.
+func (x *XRD) OffersClaims() bool {
+	if x.Spec.ClaimNames == nil {
+		return false
+	}
+	scope := x.EffectiveScope()
+	return scope == "" || scope == "LegacyCluster"
+}
+
+// ManifestTarget describes the resource users create from an XRD
+type ManifestTarget struct {
+	Kind       string
+	Resource   string // claim or composite resource
+	Namespaced bool
+}
+
+// ManifestTarget returns what users create: a claim, which always lives in a
+// namespace, when the XRD offers claims, and otherwise the composite resource
+func (x *XRD) ManifestTarget() ManifestTarget {
+	if x.OffersClaims() {
+		return ManifestTarget{Kind: x.Spec.ClaimNames.Kind, Resource: "claim", Namespaced: true}
+	}
+	resource := "composite resource"
+	if x.IsCRD() {
+		resource = "resource"
+	}
+	return ManifestTarget{Kind: x.Spec.Names.Kind, Resource: resource, Namespaced: x.EffectiveScope() == "Namespaced"}
+}
+
+// defaultVersionIndex returns the index of the referenceable (storage) version,
+// falling back to the first served version and then to the first version
+func (x *XRD) defaultVersionIndex() int {
+	for i := range x.Spec.Versions {
+		if x.Spec.Versions[i].Referenceable || x.Spec.Versions[i].Storage {
+			return i
+		}
+	}
+	for i := range x.Spec.Versions {
+		if x.Spec.Versions[i].Served {
+			return i
+		}
+	}
+	return 0
+}
+
+// versionIndex returns the index of the named version, or of the default version
+// when name is empty
+func (x *XRD) versionIndex(name string) (int, error) {
+	if name == "" {
+		return x.defaultVersionIndex(), nil
+	}
+
+	names := make([]string, len(x.Spec.Versions))
+	for i, v := range x.Spec.Versions {
+		if v.Name == name {
+			return i, nil
+		}
+		names[i] = v.Name
+	}
+	return 0, fmt.Errorf("version %q not found in XRD: use one of %s", name, strings.Join(names, ", "))
 }
 
 // Generate generates documentation from an XRD struct
 func (g *Generator) Generate(xrd *XRD, opts Options) (string, error) {
+	var b strings.Builder
+	if err := g.GenerateTo(&b, xrd, opts); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// GenerateTo generates documentation from an XRD struct, executing the templates
+// directly against w. Options are checked first, but w may receive partial output
+// when rendering or writing fails.
+func (g *Generator) GenerateTo(w io.Writer, xrd *XRD, opts Options) error {
+	// Only a missing version stops rendering; the other structural problems are
+	// reported by Validate
 	if len(xrd.Spec.Versions) == 0 {
-		return "", fmt.Errorf("no versions found in XRD")
+		return fmt.Errorf("no versions found in XRD")
 	}
 
-	// Use the first served version
-	var version *XRDVersion
-	for i := range xrd.Spec.Versions {
-		if xrd.Spec.Versions[i].Served {
-			version = &xrd.Spec.Versions[i]
-			break
+	// Use the requested version, or the storage version
+	index, err := xrd.versionIndex(opts.Version)
+	if err != nil {
+		return err
+	}
+	version := &xrd.Spec.Versions[index]
+
+	specFields, statusFields := g.ExtractFields(version, opts)
+
+	switch opts.Flavor {
+	case "", "github", "techdocs":
+	case "mkdocs":
+		opts.Flavor = "techdocs"
+	default:
+		return fmt.Errorf("unknown markdown flavor %q", opts.Flavor)
+	}
+
+	switch opts.ExampleMode {
+	case "", "required", "full", "none":
+	default:
+		return fmt.Errorf("unknown example mode %q: use none, required or full", opts.ExampleMode)
+	}
+
+	switch opts.SortMode {
+	case "", "required-first", "alphabetical", "schema":
+	default:
+		return fmt.Errorf("unknown sort mode %q: use required-first, alphabetical or schema", opts.SortMode)
+	}
+
+	switch opts.TableFormat {
+	case "", "table":
+	case "compact", "wide":
+		if opts.Format != "" && opts.Format != "markdown" {
+			return markdownOnly("TableFormat=" + opts.TableFormat)
 		}
+	default:
+		return fmt.Errorf("unknown table format %q: use table, compact or wide", opts.TableFormat)
+	}
+
+	if _, ok := indentStyles[opts.IndentStyle]; !ok {
+		return fmt.Errorf("unknown indent style %q: use arrows, dots or plain", opts.IndentStyle)
+	}
+
+	switch opts.Audience {
+	case "", "platform", "consumer":
+	default:
+		return fmt.Errorf("unknown audience %q: use platform or consumer", opts.Audience)
 	}
-	if version == nil {
-		version = &xrd.Spec.Versions[0]
+
+	switch opts.Format {
+	case "", "markdown":
+	case "csv":
+		if opts.Only != "" {
+			return markdownOnly("Only")
+		}
+		return g.writeCSV(w, specFields, statusFields, opts.ExcludeTypes)
+	case "json":
+		if opts.Only != "" {
+			return markdownOnly("Only")
+		}
+		doc, err := g.generateJSON(xrd, version, specFields, statusFields)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, doc)
+		return err
+	case "html":
+		var options []string
+		if opts.Only != "" {
+			options = append(options, "Only")
+		}
+		if opts.AllVersions {
+			options = append(options, "AllVersions")
+		}
+		if opts.Audience == "consumer" {
+			options = append(options, "Audience=consumer")
+		}
+		if len(options) > 0 {
+			return markdownOnly(options...)
+		}
+		return g.writeHTML(w, xrd, version, specFields, statusFields, nil, opts)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.Format)
 	}
 
-	// Extract spec fields
-	specFields := g.extractFields(version.Schema.OpenAPIV3Schema, "spec", []string{}, 0, opts.ShowNested)
+	var removed []RemovedFields
+	if opts.ShowRemoved {
+		removed = g.removedFields(xrd, index)
+	}
 
-	// Always include status fields (they're part of the API!)
-	statusFields := g.extractFields(version.Schema.OpenAPIV3Schema, "status", []string{}, 0, opts.ShowNested)
+	if opts.AllVersions && opts.Only != "" {
+		return &OptionError{Options: []string{"Only", "AllVersions"}, Reason: "can't be combined"}
+	}
+	if opts.AllVersions && opts.Version != "" {
+		return &OptionError{Options: []string{"Version", "AllVersions"}, Reason: "can't be combined"}
+	}
 
 	// Generate markdown
-	return g.generateMarkdown(xrd, version, specFields, statusFields)
+	return g.writeMarkdown(w, xrd, version, specFields, statusFields, removed, opts)
+}
+
+// ExtractFields returns the documented spec and status fields of a version as
+// trees, nested fields under their parent, applying the field options such as
+// SortMode, RequiredOnly and HideStatus. Nothing is rendered.
+func (g *Generator) ExtractFields(version *XRDVersion, opts Options) (spec, status []Field) {
+	specFields := g.extractFields(version.Schema.OpenAPIV3Schema, "spec", []string{}, 0, opts)
+	if opts.RequiredOnly {
+		specFields = g.filterRequiredFields(specFields)
+	}
+
+	// Status fields are part of the API too, unless the status is hidden
+	if opts.HideStatus {
+		return specFields, nil
+	}
+	statusFields := g.extractFields(version.Schema.OpenAPIV3Schema, "status", []string{}, 0, opts)
+	if opts.IncludeStandardStatus {
+		statusFields = withStandardStatus(statusFields, opts)
+	}
+	if opts.StatusDescribedOnly || len(opts.StatusInclude) > 0 {
+		statusFields = g.filterStatusFields(statusFields, opts)
+	}
+
+	// Fields are sorted during extraction; the standard status fields are added
+	// after it, so the top level is sorted again to place them like the others
+	if opts.IncludeStandardStatus && opts.SortMode != "schema" {
+		sortFields(statusFields, nil, opts.SortMode)
+	}
+
+	return specFields, statusFields
+}
+
+// removedFields compares the version at index against the earlier served versions
+// (by Kubernetes version priority) and returns the field paths each of them had
+// that the version no longer has
+func (g *Generator) removedFields(xrd *XRD, index int) []RemovedFields {
+	var result []RemovedFields
+
+	version := xrd.Spec.Versions[index]
+	current := make(map[string]bool)
+	collectFieldPaths(version.Schema.OpenAPIV3Schema, "", current)
+
+	var earlierVersions []XRDVersion
+	for i, v := range xrd.Spec.Versions {
+		if i != index && v.Served && versionLess(v.Name, version.Name) {
+			earlierVersions = append(earlierVersions, v)
+		}
+	}
+	sort.Slice(earlierVersions, func(i, j int) bool {
+		return versionLess(earlierVersions[i].Name, earlierVersions[j].Name)
+	})
+
+	for _, earlier := range earlierVersions {
+		previous := make(map[string]bool)
+		collectFieldPaths(earlier.Schema.OpenAPIV3Schema, "", previous)
+
+		var paths []string
+		for fieldPath := range previous {
+			if !current[fieldPath] {
+				paths = append(paths, fieldPath)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		sort.Strings(paths)
+		result = append(result, RemovedFields{Since: earlier.Name, Paths: paths})
+	}
+
+	return result
+}
+
+// versionPattern matches Kubernetes version names like v1, v1beta2 or v2alpha1
+var versionPattern = regexp.MustCompile(`^v(\d+)(?:(alpha|beta)(\d+))?$`)
+
+// versionLess reports whether version a has a lower Kubernetes version priority than b.
+// GA versions sort above beta, beta above alpha; names that don't follow the
+// convention sort below all conforming names, alphabetically.
+func versionLess(a, b string) bool {
+	ma, mb := versionPattern.FindStringSubmatch(a), versionPattern.FindStringSubmatch(b)
+	switch {
+	case ma == nil && mb == nil:
+		return a < b
+	case ma == nil:
+		return true
+	case mb == nil:
+		return false
+	}
+
+	stability := map[string]int{"alpha": 0, "beta": 1, "": 2}
+	if stability[ma[2]] != stability[mb[2]] {
+		return stability[ma[2]] < stability[mb[2]]
+	}
+
+	majorA, _ := strconv.Atoi(ma[1])
+	majorB, _ := strconv.Atoi(mb[1])
+	if majorA != majorB {
+		return majorA < majorB
+	}
+
+	minorA, _ := strconv.Atoi(ma[3])
+	minorB, _ := strconv.Atoi(mb[3])
+	return minorA < minorB
+}
+
+// collectFieldPaths records the dotted path of every property in the schema
+func collectFieldPaths(schema OpenAPISchema, prefix string, paths map[string]bool) {
+	for name, prop := range schema.Properties {
+		fieldPath := joinPath(prefix, name)
+		paths[fieldPath] = true
+		collectFieldPaths(prop, fieldPath, paths)
+	}
 }
 
 // extractFields recursively extracts fields from the schema
-func (g *Generator) extractFields(schema OpenAPISchema, prefix string, required []string, level int, showNested bool) []Field {
+func (g *Generator) extractFields(schema OpenAPISchema, prefix string, required []string, level int, opts Options) []Field {
 	var fields []Field
 
 	if schema.Properties == nil {
@@ -147,76 +722,197 @@ func (g *Generator) extractFields(schema OpenAPISchema, prefix string, required
 		return fields
 	}
 
-	for name, prop := range targetProp.Properties {
-		field := Field{
-			Name:        name,
-			Type:        g.formatType(prop),
-			Description: prop.Description,
-			Required:    contains(targetProp.Required, name),
-			Default:     g.formatDefault(prop.Default),
-			Constraints: g.formatConstraints(prop),
-			Level:       level,
+	return g.extractNestedFields(targetProp, prefix, level, opts)
+}
+
+// filterRequiredFields keeps only required fields, descending into required parents only
+func (g *Generator) filterRequiredFields(fields []Field) []Field {
+	var result []Field
+
+	for _, field := range fields {
+		if !field.Required {
+			continue
 		}
+		field.Nested = g.filterRequiredFields(field.Nested)
+		result = append(result, field)
+	}
 
-		// If this is an object and we want to show nested fields
-		if showNested && prop.Type == "object" && prop.Properties != nil {
-			field.Nested = g.extractNestedFields(prop, level+1, showNested)
+	return result
+}
+
+// filterStatusFields keeps the status fields selected by the status filter options.
+// Parents of selected fields are kept so the nesting stays intact.
+func (g *Generator) filterStatusFields(fields []Field, opts Options) []Field {
+	var result []Field
+
+	for _, field := range fields {
+		field.Nested = g.filterStatusFields(field.Nested, opts)
+		if len(field.Nested) > 0 || g.statusFieldSelected(field, opts) {
+			result = append(result, field)
 		}
+	}
 
-		fields = append(fields, field)
+	return result
+}
+
+// statusFieldSelected reports whether a status field passes the status filters.
+// Globs match the path relative to status, e.g. "atProvider.*".
+func (g *Generator) statusFieldSelected(field Field, opts Options) bool {
+	if opts.StatusDescribedOnly && field.Description == "" {
+		return false
+	}
+	if len(opts.StatusInclude) == 0 {
+		return true
 	}
 
-	return fields
+	relPath := strings.TrimPrefix(field.Path, "status.")
+	for _, pattern := range opts.StatusInclude {
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
 }
 
-// extractNestedFields extracts nested object fields
-func (g *Generator) extractNestedFields(schema OpenAPISchema, level int, showNested bool) []Field {
-	var fields []Field
+// maxNestingDepth caps how deep nested fields are extracted
+const maxNestingDepth = 10
+
+// itemTypePrefix marks the type of fields set on each item of a list of objects
+const itemTypePrefix = "[] "
 
+// extractNestedFields extracts nested object fields
+func (g *Generator) extractNestedFields(schema OpenAPISchema, fieldPath string, level int, opts Options) []Field {
 	if schema.Properties == nil {
-		return fields
+		return nil
 	}
 
+	conditional := conditionalRequirements(schema)
+	alternatives := alternativeNotes(schema)
+
+	fields := make([]Field, 0, len(schema.Properties))
 	for name, prop := range schema.Properties {
 		field := Field{
 			Name:        name,
+			Path:        joinPath(fieldPath, name),
 			Type:        g.formatType(prop),
 			Description: prop.Description,
 			Required:    contains(schema.Required, name),
+			Deprecated:  prop.deprecated(),
 			Default:     g.formatDefault(prop.Default),
+			Example:     g.formatDefault(prop.Example),
 			Constraints: g.formatConstraints(prop),
 			Level:       level,
+			schema:      prop,
+		}
+
+		if opts.CompositionDefaults != nil {
+			field.Effective = effectiveDefault(field, opts.CompositionDefaults[field.Path])
+		}
+
+		if note := g.quantityNote(name, prop, opts); note != "" {
+			field.Constraints = joinNonEmpty(", ", field.Constraints, note)
+		}
+		if note, ok := conditional[name]; ok && !field.Required {
+			field.Constraints = joinNonEmpty(", ", field.Constraints, note)
+		}
+		for _, note := range alternatives[name] {
+			field.Constraints = joinNonEmpty(", ", field.Constraints, note)
 		}
 
-		// Recursively extract if nested object
-		if showNested && prop.Type == "object" && prop.Properties != nil {
-			field.Nested = g.extractNestedFields(prop, level+1, showNested)
+		// Recursively extract if nested object, the value object of a map, or the
+		// item object of a list. Depth is capped in case the schema refers to itself.
+		if opts.ShowNested && level < maxNestingDepth {
+			if prop.Type == "object" && prop.Properties != nil {
+				field.Nested = g.extractNestedFields(prop, field.Path, level+1, opts)
+			} else if values, ok := prop.mapValues(); ok && values.Properties != nil {
+				field.Nested = g.extractNestedFields(*values, field.Path+".*", level+1, opts)
+			} else if prop.Type == "array" && prop.Items != nil && prop.Items.Properties != nil {
+				field.Nested = g.extractNestedFields(*prop.Items, field.Path+"[]", level+1, opts)
+				for i := range field.Nested {
+					field.Nested[i].Type = itemTypePrefix + field.Nested[i].Type
+				}
+			}
 		}
 
 		fields = append(fields, field)
 	}
 
+	// Sort each level once as it's built
+	sortFields(fields, schema.propertyOrder, opts.SortMode)
+
 	return fields
 }
 
+// sortFields orders one level of fields by mode: required first, then deprecated
+// last, then alphabetically (the default), alphabetically, or in schema declaration order
+func sortFields(fields []Field, order []string, mode string) {
+	switch mode {
+	case "schema":
+		position := make(map[string]int, len(order))
+		for i, name := range order {
+			position[name] = i
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return position[fields[i].Name] < position[fields[j].Name]
+		})
+	case "alphabetical":
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].Name < fields[j].Name
+		})
+	default:
+		sort.Slice(fields, func(i, j int) bool {
+			if fields[i].Required != fields[j].Required {
+				return fields[i].Required
+			}
+			if fields[i].Deprecated != fields[j].Deprecated {
+				return !fields[i].Deprecated
+			}
+			return fields[i].Name < fields[j].Name
+		})
+	}
+}
+
 // formatType formats the field type
 func (g *Generator) formatType(schema OpenAPISchema) string {
 	if schema.Type == "array" && schema.Items != nil {
 		return fmt.Sprintf("list(%s)", g.formatType(*schema.Items))
 	}
+	if values, ok := schema.mapValues(); ok && len(schema.Properties) == 0 {
+		valueType := g.formatType(*values)
+		if valueType == "" {
+			valueType = "any"
+		}
+		return fmt.Sprintf("map[string]%s", valueType)
+	}
+	if schema.freeForm() && (schema.Type == "object" || schema.Type == "") {
+		return "object (free-form)"
+	}
 	if schema.Type == "object" {
 		return "object"
 	}
+	if schema.XKubernetesIntOrString {
+		return "int-or-string"
+	}
+	typ := schema.Type
 	if len(schema.Enum) > 0 {
-		return "string"
+		typ = "string"
 	}
-	return schema.Type
+	if schema.Format != "" {
+		typ = fmt.Sprintf("%s (%s)", typ, schema.Format)
+	}
+	return typ
 }
 
-// formatDefault formats the default value
+// formatDefault formats the default value. Objects and lists are rendered as
+// compact JSON, e.g. {"replicas":3}, so they fit in a table cell.
 func (g *Generator) formatDefault(value interface{}) string {
-	if value == nil {
+	switch value.(type) {
+	case nil:
 		return ""
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
 	}
 	return fmt.Sprintf("%v", value)
 }
@@ -233,11 +929,15 @@ func (g *Generator) formatConstraints(schema OpenAPISchema) string {
 		constraints = append(constraints, fmt.Sprintf("Allowed: %s", strings.Join(enumVals, ", ")))
 	}
 
-	if schema.Minimum != nil {
+	if schema.Minimum != nil && schema.ExclusiveMinimum {
+		constraints = append(constraints, fmt.Sprintf("> %v", *schema.Minimum))
+	} else if schema.Minimum != nil {
 		constraints = append(constraints, fmt.Sprintf("Min: %v", *schema.Minimum))
 	}
 
-	if schema.Maximum != nil {
+	if schema.Maximum != nil && schema.ExclusiveMaximum {
+		constraints = append(constraints, fmt.Sprintf("< %v", *schema.Maximum))
+	} else if schema.Maximum != nil {
 		constraints = append(constraints, fmt.Sprintf("Max: %v", *schema.Maximum))
 	}
 
@@ -249,110 +949,541 @@ func (g *Generator) formatConstraints(schema OpenAPISchema) string {
 		constraints = append(constraints, fmt.Sprintf("MaxItems: %d", *schema.MaxItems))
 	}
 
+	if schema.Pattern != nil {
+		constraints = append(constraints, fmt.Sprintf("Pattern: %s", section.CodeTableCell(*schema.Pattern)))
+	}
+
+	if schema.MinLength != nil {
+		constraints = append(constraints, fmt.Sprintf("MinLength: %d", *schema.MinLength))
+	}
+
+	if schema.MaxLength != nil {
+		constraints = append(constraints, fmt.Sprintf("MaxLength: %d", *schema.MaxLength))
+	}
+
+	// An object with fixed properties that also accepts other keys shows as object,
+	// so the type of the other keys' values is noted here
+	if values, ok := schema.mapValues(); ok && len(schema.Properties) > 0 {
+		valueType := g.formatType(*values)
+		if valueType == "" {
+			valueType = "any"
+		}
+		constraints = append(constraints, fmt.Sprintf("Additional keys: %s", valueType))
+	}
+
+	if schema.freeForm() {
+		constraints = append(constraints, "Accepts arbitrary keys")
+	}
+
+	// Constraints on the items of a list, e.g. the allowed values of a list(string)
+	if schema.Type == "array" && schema.Items != nil {
+		if items := g.formatConstraints(*schema.Items); items != "" {
+			constraints = append(constraints, "Items: "+items)
+		}
+	}
+
 	return strings.Join(constraints, ", ")
 }
 
-// generateMarkdown generates the final markdown output
-func (g *Generator) generateMarkdown(xrd *XRD, version *XRDVersion, specFields []Field, statusFields []Field) (string, error) {
-	// Sort fields: required first, then alphabetically
-	sort.Slice(specFields, func(i, j int) bool {
-		if specFields[i].Required != specFields[j].Required {
-			return specFields[i].Required
+// quantityNote returns formatting guidance for fields holding Kubernetes resource
+// quantities: string or int-or-string fields whose name ends in a configured keyword,
+// e.g. memory or requestCPU but not storageClassName
+func (g *Generator) quantityNote(name string, schema OpenAPISchema, opts Options) string {
+	if schema.Type != "string" && !schema.XKubernetesIntOrString {
+		return ""
+	}
+
+	keywords := opts.QuantityFields
+	if keywords == nil {
+		keywords = DefaultQuantityFields
+	}
+
+	tokens := nameTokens(name)
+	if len(tokens) == 0 {
+		return ""
+	}
+	last := tokens[len(tokens)-1]
+	matched := ""
+	for _, k := range keywords {
+		if strings.EqualFold(last, k) {
+			matched = strings.ToLower(k)
+			break
 		}
-		return specFields[i].Name < specFields[j].Name
-	})
+	}
 
-	if len(statusFields) > 0 {
-		sort.Slice(statusFields, func(i, j int) bool {
-			return statusFields[i].Name < statusFields[j].Name
-		})
+	switch matched {
+	case "":
+		return ""
+	case "cpu":
+		return "Kubernetes quantity (e.g. `500m`, `2`)"
+	default:
+		return "Kubernetes quantity (e.g. `100Mi`, `2`)"
 	}
+}
 
-	// Flatten nested fields for table display
-	flatSpecFields := g.flattenFields(specFields)
-	flatStatusFields := g.flattenFields(statusFields)
-
-	tmpl := `# {{ .XRD.Spec.Names.Kind }}
-
-{{ .Version.Schema.OpenAPIV3Schema.Description }}
-
-**API Group:** {{ .XRD.Spec.Group }}  
-**API Version:** {{ .Version.Name }}  
-**Kind:** {{ .XRD.Spec.Names.Kind }}  
-{{ if .XRD.Spec.ClaimNames }}**Claim Kind:** {{ .XRD.Spec.ClaimNames.Kind }}  {{ end }}
-
-## Spec Fields
-
-| Name | Type | Description | Required | Default | Constraints |
-|------|------|-------------|----------|---------|-------------|
-{{ range .SpecFields -}}
-| {{ if gt .Level 0 }}{{ indent .Level }}{{ end }}{{ .Name }} | {{ .Type }} | {{ .Description }} | {{ if .Required }}✅{{ else }}❌{{ end }} | {{ if .Default }}` + "`{{ .Default }}`" + `{{ else }}-{{ end }} | {{ if .Constraints }}{{ .Constraints }}{{ else }}-{{ end }} |
-{{ end }}
-{{ if .StatusFields }}
-## Status Fields
-
-| Name | Type | Description |
-|------|------|-------------|
-{{ range .StatusFields -}}
-| {{ if gt .Level 0 }}{{ indent .Level }}{{ end }}{{ .Name }} | {{ .Type }} | {{ .Description }} |
-{{ end }}
-{{ end }}
-## Example
-
-` + "```yaml" + `
-apiVersion: {{ .XRD.Spec.Group }}/{{ .Version.Name }}
-kind: {{ if .XRD.Spec.ClaimNames }}{{ .XRD.Spec.ClaimNames.Kind }}{{ else }}{{ .XRD.Spec.Names.Kind }}{{ end }}
-metadata:
-  name: example
-spec:
-  # Add your spec fields here
-` + "```" + `
-`
+// nameTokens splits a field name into its lowercase words, breaking on case
+// changes and separators, e.g. maxCPUCores gives max, cpu and cores
+func nameTokens(name string) []string {
+	var (
+		tokens []string
+		word   []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return tokens
+}
 
+// markdownData is the data the XRD document template is rendered with. Custom
+// templates are executed against it too, so its fields are documented in the README.
+type markdownData struct {
+	Title         string        // Document heading
+	TechDocs      bool          // Render MkDocs admonitions
+	Effective     bool          // Show the Effective Default column (bundle mode)
+	AllVersions   bool          // Every version is documented
+	ClaimOnly     []ScopedField // Fields only the claim has
+	CompositeOnly []ScopedField // Fields only the composite has
+	XRD           *XRD
+	Version       *XRDVersion // Documented version
+	SpecFields    []Field     // Flattened spec fields, nested fields following their parent
+	SpecCounts    FieldCounts // Spec field counts, from before flattening
+	StatusFields  []Field     // Flattened status fields
+	Removed       []RemovedFields
+	Example       string // Example manifest YAML
+	ExampleTarget ManifestTarget
+	Quickstart    string // Minimal manifest YAML, empty unless enabled
+
+	ValidationRules []ValidationRule
+	PrinterColumns  []PrinterColumn
+	RequiredFields  []Field // Flattened required spec fields
+	Compositions    []BundledComposition
+	ConnectionKeys  []string
+	Matrix          *VersionMatrix // Nil unless the version matrix is enabled
+	ShowExamples    bool
+	FullPaths       bool
+	Labels          []MetadataEntry // XRD labels selected by MetadataPrefixes
+	Annotations     []MetadataEntry // XRD annotations selected by MetadataPrefixes
+	HideMetadata    bool
+	HideStatus      bool
+	HideExample     bool
+}
+
+// fieldCell is the data of the field-name template, which renders a field's name cell
+type fieldCell struct {
+	Field     Field
+	FullPaths bool
+}
+
+// fieldRules lists the CEL rules declared on a field's own schema as code spans
+func fieldRules(f Field) string {
+	var rules []string
+	for _, rule := range validationRules(f.schema) {
+		rules = append(rules, section.CodeTableCell(rule.Rule))
+	}
+	return strings.Join(rules, "; ")
+}
+
+// FieldCounts counts the documented fields at the top level and including nested fields
+type FieldCounts struct {
+	TopLevel         int
+	TopLevelRequired int
+	Total            int
+	TotalRequired    int
+}
+
+// countSpecFields counts a field tree, so nested fields are counted once
+func countSpecFields(fields []Field) FieldCounts {
+	counts := FieldCounts{TopLevel: len(fields), Total: countFields(fields)}
+	for _, f := range fields {
+		if f.Required {
+			counts.TopLevelRequired++
+		}
+	}
+	for _, f := range appendFlattened(nil, fields) {
+		if f.Required {
+			counts.TotalRequired++
+		}
+	}
+	return counts
+}
+
+// indentStyles render the marker in front of a nested field name, by indent style
+var indentStyles = map[string]func(level int) string{
+	"":       indentArrows,
+	"arrows": indentArrows,
+	"dots": func(level int) string {
+		return strings.Repeat("··", level) + " "
+	},
+	// Plain spaces for renderers without &nbsp;; the arrow keeps the nesting
+	// visible where leading spaces are trimmed
+	"plain": func(level int) string {
+		return strings.Repeat("  ", level) + "↳ "
+	},
+}
+
+func indentArrows(level int) string {
+	return strings.Repeat("&nbsp;&nbsp;", level) + "↳ "
+}
+
+// versionSections are the sections repeated for every version with AllVersions
+var versionSections = []string{"quickstart", "spec", "validation", "status", "columns", "example"}
+
+// writeMarkdown generates the final markdown output
+func (g *Generator) writeMarkdown(w io.Writer, xrd *XRD, version *XRDVersion, specFields []Field, statusFields []Field, removed []RemovedFields, opts Options) error {
 	funcMap := template.FuncMap{
-		"indent": func(level int) string {
-			return strings.Repeat("&nbsp;&nbsp;", level) + "↳ "
+		"indent": indentStyles[opts.IndentStyle],
+		"indentBlock": func(text string) string {
+			lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+			for i, line := range lines {
+				if line != "" {
+					lines[i] = "    " + line
+				}
+			}
+			return strings.Join(lines, "\n")
+		},
+		"fieldCell": func(f Field, fullPaths bool) fieldCell {
+			return fieldCell{Field: f, FullPaths: fullPaths}
 		},
+		"fieldRules": fieldRules,
+		"codeList":   codeList,
+		"cell":       section.EscapeTableCell,
+		"codeCell":   section.CodeTableCell,
+		"join":       strings.Join,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+	}
+
+	t, err := template.New("markdown").Funcs(funcMap).Parse(xrdTemplate)
+	if err != nil {
+		return err
+	}
+
+	// The spec section renders the table of the chosen format, wherever it's used
+	if opts.TableFormat == "compact" || opts.TableFormat == "wide" {
+		if _, err := t.AddParseTree("spec", t.Lookup("spec-"+opts.TableFormat).Tree); err != nil {
+			return err
+		}
+	}
+
+	// A custom template can use the built-in sections, e.g. {{ template "spec" . }}
+	var custom *template.Template
+	if opts.TemplateFile != "" {
+		if opts.Only != "" {
+			return &OptionError{Options: []string{"TemplateFile", "Only"}, Reason: "can't be combined"}
+		}
+		if opts.AllVersions {
+			return &OptionError{Options: []string{"TemplateFile", "AllVersions"}, Reason: "can't be combined"}
+		}
+		content, err := os.ReadFile(opts.TemplateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		if custom, err = t.New(filepath.Base(opts.TemplateFile)).Parse(string(content)); err != nil {
+			return fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
+
+	data, err := g.templateData(xrd, version, specFields, statusFields, removed, opts)
+	if err != nil {
+		return err
+	}
+
+	if custom != nil {
+		if err := custom.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	}
+
+	sections := xrdSections
+	if opts.Audience == "consumer" {
+		sections = consumerSections
+	}
+	allVersions := opts.AllVersions && opts.Audience != "consumer"
+
+	// Sections stream to w unless the document needs reworking once rendered
+	if !allVersions && opts.Only == "" && !opts.TOC {
+		return section.Write(w, t, sections, data)
+	}
+
+	var doc string
+	if allVersions {
+		doc, err = g.renderAllVersions(t, data, opts)
+	} else {
+		doc, err = section.Render(t, sections, opts.Only, !opts.NoHeading, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.TOC && opts.Only == "" {
+		// The table of contents goes right below the title and description
+		header, err := section.Render(t, []string{"header"}, "", true, data)
+		if err != nil {
+			return err
+		}
+		body := strings.TrimPrefix(doc, header)
+		doc = header + tableOfContents(body, data.TechDocs) + body
+	}
+
+	_, err = io.WriteString(w, doc)
+	return err
+}
+
+// templateData builds the data the document templates are rendered with
+func (g *Generator) templateData(xrd *XRD, version *XRDVersion, specFields, statusFields []Field, removed []RemovedFields, opts Options) (markdownData, error) {
+	var matrix *VersionMatrix
+	if opts.ShowMatrix {
+		m := g.buildVersionMatrix(xrd)
+		matrix = &m
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = xrd.Spec.Names.Kind
+		if opts.Audience == "consumer" && xrd.OffersClaims() {
+			title = xrd.Spec.ClaimNames.Kind
+		}
+	}
+
+	data := markdownData{
+		Title:         title,
+		TechDocs:      opts.Flavor == "techdocs",
+		Effective:     opts.CompositionDefaults != nil,
+		AllVersions:   opts.AllVersions,
+		ClaimOnly:     claimOnlyFields,
+		CompositeOnly: compositeOnlyFields,
+		XRD:           xrd,
+		Removed:       removed,
+
+		Compositions:   opts.Compositions,
+		ConnectionKeys: connectionKeys(xrd, opts.Compositions),
+		Matrix:         matrix,
+		ShowExamples:   opts.ShowExamples,
+		FullPaths:      opts.FullPaths,
+		Labels:         selectMetadata(xrd.Metadata.Labels, opts.MetadataPrefixes),
+		Annotations:    selectMetadata(xrd.Metadata.Annotations, opts.MetadataPrefixes),
+		HideMetadata:   opts.HideMetadata,
+		HideStatus:     opts.HideStatus,
+		HideExample:    opts.HideExample || opts.ExampleMode == "none",
+	}
+	if err := g.fillVersion(&data, xrd, version, specFields, statusFields, opts); err != nil {
+		return markdownData{}, err
+	}
+	return data, nil
+}
+
+// fillVersion sets the per-version parts of the template data: the field tables,
+// validation rules, example and quick start of version
+func (g *Generator) fillVersion(data *markdownData, xrd *XRD, version *XRDVersion, specFields, statusFields []Field, opts Options) error {
+	// Flatten nested fields for table display
+	flatSpecFields := excludeFieldTypes(g.flattenFields(specFields), opts.ExcludeTypes)
+	flatStatusFields := excludeFieldTypes(g.flattenFields(statusFields), opts.ExcludeTypes)
+	requiredFields := g.flattenFields(g.filterRequiredFields(specFields))
+
+	if opts.FieldAnchors {
+		anchorSlug := fieldSlugs.slugify
+		if opts.AllVersions {
+			// Keep anchors unique across the versions of the document
+			anchorSlug = func(fieldPath string) string { return fieldSlugs.slugify(version.Name + "." + fieldPath) }
+		}
+		anchors := assignAnchors(anchorSlug, flatSpecFields, flatStatusFields)
+		linkFieldReferences(flatSpecFields, anchors)
+		linkFieldReferences(flatStatusFields, anchors)
+
+		// The consumer "What You Set" table takes the place of the spec table
+		for i := range requiredFields {
+			requiredFields[i].Anchor = anchors[requiredFields[i].Path]
+		}
+		linkFieldReferences(requiredFields, anchors)
+	}
+
+	example, err := g.buildExample(xrd, version, opts.ExampleMode)
+	if err != nil {
+		return err
+	}
+	if opts.ValidateExample {
+		if err := g.validateExample(example, version); err != nil {
+			return fmt.Errorf("%s: %w", version.Name, err)
+		}
+	}
+
+	var quickstart string
+	if opts.Quickstart {
+		if quickstart, err = g.buildQuickstart(xrd, version); err != nil {
+			return err
+		}
 	}
 
-	t, err := template.New("markdown").Funcs(funcMap).Parse(tmpl)
+	data.Version = version
+	data.SpecFields = flatSpecFields
+	data.SpecCounts = countSpecFields(specFields)
+	data.StatusFields = flatStatusFields
+	data.Example = example
+	data.ExampleTarget = xrd.ManifestTarget()
+	data.Quickstart = quickstart
+	data.ValidationRules = validationRulesOf(version, opts)
+	data.PrinterColumns = version.printerColumns()
+	data.RequiredFields = requiredFields
+	return nil
+}
+
+// renderAllVersions renders the shared header once, followed by a section per
+// version with its own tables and example, and the claim notes
+func (g *Generator) renderAllVersions(t *template.Template, data markdownData, opts Options) (string, error) {
+	head, err := section.Render(t, []string{"header", "metadata", "removed", "matrix"}, "", true, data)
 	if err != nil {
 		return "", err
 	}
 
-	data := struct {
-		XRD          *XRD
-		Version      *XRDVersion
-		SpecFields   []Field
-		StatusFields []Field
-	}{
-		XRD:          xrd,
-		Version:      version,
-		SpecFields:   flatSpecFields,
-		StatusFields: flatStatusFields,
+	var b strings.Builder
+	b.WriteString(head)
+
+	for i := range data.XRD.Spec.Versions {
+		version := &data.XRD.Spec.Versions[i]
+		specFields, statusFields := g.ExtractFields(version, opts)
+		if err := g.fillVersion(&data, data.XRD, version, specFields, statusFields, opts); err != nil {
+			return "", err
+		}
+
+		body, err := section.Render(t, versionSections, "", true, data)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "\n## Version %s\n\n_%s_\n%s", version.Name, versionStatus(version), demoteHeadings(body))
 	}
 
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
+	// The claim and composite differences don't depend on the version
+	claims, err := section.Render(t, []string{"claims"}, "", true, data)
+	if err != nil {
 		return "", err
 	}
+	b.WriteString(strings.Replace(claims, "\n### ", "\n## ", 1))
+
+	return b.String(), nil
+}
 
-	return buf.String(), nil
+// versionStatus describes whether a version is served and referenceable
+func versionStatus(version *XRDVersion) string {
+	status := "Served"
+	if !version.Served {
+		status = "Not served"
+	}
+	if version.Referenceable {
+		// Crossplane stores composite resources in the referenceable version
+		status += ", referenceable (storage version)"
+	}
+	return status
 }
 
-// flattenFields converts nested field structure to flat list for table display
+// demoteHeadings moves every markdown heading down one level
+func demoteHeadings(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flattenFields converts nested field structure to flat list for table display.
+// The result is allocated once, keeping flattening linear in the number of fields.
 func (g *Generator) flattenFields(fields []Field) []Field {
-	var result []Field
+	result := make([]Field, 0, countFields(fields))
+	return appendFlattened(result, fields)
+}
+
+func appendFlattened(dst []Field, fields []Field) []Field {
 	for _, field := range fields {
-		result = append(result, field)
+		dst = append(dst, field)
 		if len(field.Nested) > 0 {
-			result = append(result, g.flattenFields(field.Nested)...)
+			dst = appendFlattened(dst, field.Nested)
 		}
 	}
-	return result
+	return dst
+}
+
+func countFields(fields []Field) int {
+	n := len(fields)
+	for _, field := range fields {
+		n += countFields(field.Nested)
+	}
+	return n
+}
+
+// Helper functions
+
+// validationRulesOf collects the validation rules of the schema root and the documented
+// spec and status schemas
+func validationRulesOf(version *XRDVersion, opts Options) []ValidationRule {
+	root := version.Schema.OpenAPIV3Schema
+
+	// Rules on the schema root typically relate spec and status fields
+	var rules []ValidationRule
+	for _, rule := range validationRules(root) {
+		rule.Path = "(root)"
+		rules = append(rules, rule)
+	}
+
+	rules = append(rules, collectValidationRules(root.Properties["spec"], "spec")...)
+	if !opts.HideStatus {
+		rules = append(rules, collectValidationRules(root.Properties["status"], "status")...)
+	}
+	return rules
+}
+
+// codeList formats items as a comma-separated list of inline code spans
+func codeList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
 }
 
-// Helper function
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -361,3 +1492,67 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// excludeFieldTypes drops fields of the given types from a flattened field list,
+// matching either the full type or its kind, e.g. "list" matches list(string)
+// and "string" matches string (date-time).
+// Fields below a dropped container are named by their dotted path from the
+// nearest shown ancestor so they stay unambiguous.
+func excludeFieldTypes(fields []Field, types []string) []Field {
+	if len(types) == 0 {
+		return fields
+	}
+
+	type ancestor struct {
+		level    int    // Level in the unfiltered list
+		shown    bool   // Whether the ancestor was kept
+		path     string // Path of a kept ancestor
+		newLevel int    // Level of a kept ancestor in the filtered list
+	}
+	var stack []ancestor
+
+	result := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		for len(stack) > 0 && stack[len(stack)-1].level >= f.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		// Fields on list items match by their own type, e.g. "[] object" as object
+		typ := f.Type
+		for strings.HasPrefix(typ, itemTypePrefix) {
+			typ = strings.TrimPrefix(typ, itemTypePrefix)
+		}
+		kind, _, _ := strings.Cut(typ, "(")
+		if contains(types, typ) || contains(types, strings.TrimSpace(kind)) {
+			stack = append(stack, ancestor{level: f.Level})
+			continue
+		}
+
+		// Find the nearest shown ancestor and whether a dropped one sits in between
+		var parent *ancestor
+		dropped := false
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].shown {
+				parent = &stack[i]
+				break
+			}
+			dropped = true
+		}
+
+		level := 0
+		prefix, _, _ := strings.Cut(f.Path, ".")
+		if parent != nil {
+			level = parent.newLevel + 1
+			prefix = parent.path
+		}
+		if dropped {
+			f.Name = strings.TrimPrefix(f.Path, prefix+".")
+		}
+
+		stack = append(stack, ancestor{level: f.Level, shown: true, path: f.Path, newLevel: level})
+		f.Level = level
+		result = append(result, f)
+	}
+
+	return result
+}
@@ -0,0 +1,378 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestQuantityNote(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"memory", "type: string", "Kubernetes quantity (e.g. `100Mi`, `2`)"},
+		{"requestCPU", "type: string", "Kubernetes quantity (e.g. `500m`, `2`)"},
+		{"storage", "x-kubernetes-int-or-string: true", "Kubernetes quantity (e.g. `100Mi`, `2`)"},
+		{"storageClassName", "type: string", ""},
+		{"port", "x-kubernetes-int-or-string: true", ""},
+		{"memory", "type: integer", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New().quantityNote(tt.name, testSchema(t, tt.schema), Options{})
+			if got != tt.want {
+				t.Errorf("quantityNote(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameTokens(t *testing.T) {
+	tests := map[string][]string{
+		"memory":           {"memory"},
+		"storageClassName": {"storage", "class", "name"},
+		"maxCPUCores":      {"max", "cpu", "cores"},
+		"requestCPU":       {"request", "cpu"},
+		"disk_size2GB":     {"disk", "size2", "gb"},
+	}
+	for name, want := range tests {
+		if got := nameTokens(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("nameTokens(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFormatTypeIntOrString(t *testing.T) {
+	if got := New().formatType(testSchema(t, "x-kubernetes-int-or-string: true")); got != "int-or-string" {
+		t.Errorf("formatType() = %q, want int-or-string", got)
+	}
+}
+
+func TestGenerateOptionErrors(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", "{type: object, properties: {spec: {type: object}}}"))
+
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Version: "v1", AllVersions: true}, "Version and AllVersions can't be combined"},
+		{Options{Only: "spec", AllVersions: true}, "Only and AllVersions can't be combined"},
+		{Options{Format: "csv", Only: "spec"}, "Only can only be used with markdown output"},
+		{Options{Format: "html", Only: "spec", AllVersions: true, Audience: "consumer"}, "Only, AllVersions and Audience=consumer can only be used with markdown output"},
+		{Options{TemplateFile: "custom.tmpl", Only: "spec"}, "TemplateFile and Only can't be combined"},
+	}
+	for _, tt := range tests {
+		_, err := New().Generate(xrd, tt.opts)
+		var optErr *OptionError
+		if !errors.As(err, &optErr) || optErr.Error() != tt.want {
+			t.Errorf("Generate(%+v) error = %v, want %q", tt.opts, err, tt.want)
+		}
+	}
+}
+
+func TestOptionErrorMessage(t *testing.T) {
+	err := &OptionError{Options: []string{"Version", "Audience=consumer"}, Reason: "can't be combined"}
+	flags := map[string]string{"Version": "--api-version", "Audience": "--audience"}
+
+	got := err.Message(func(option string) string { return flags[option] })
+	if want := "--api-version and --audience=consumer can't be combined"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractFieldsSortsStatusLikeSpec(t *testing.T) {
+	version := testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    required: [zone]
+    properties:
+      arn: {type: string}
+      legacy: {type: string, deprecated: true}
+      zone: {type: string}
+  status:
+    type: object
+    required: [zone]
+    properties:
+      arn: {type: string}
+      legacy: {type: string, deprecated: true}
+      zone: {type: string}
+`)
+
+	for _, mode := range []string{"", "alphabetical", "schema"} {
+		spec, status := New().ExtractFields(&version, Options{SortMode: mode})
+		if got, want := fieldNames(status), fieldNames(spec); !reflect.DeepEqual(got, want) {
+			t.Errorf("SortMode %q: status fields %v, want the spec order %v", mode, got, want)
+		}
+	}
+}
+
+func fieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// largeSchema returns a schema of about n spec fields: objects of ten string
+// fields, nested three levels deep
+func largeSchema(n int) OpenAPISchema {
+	leaf := func() OpenAPISchema {
+		obj := OpenAPISchema{Type: "object", Properties: map[string]OpenAPISchema{}}
+		for i := 0; i < 10; i++ {
+			obj.Properties[fmt.Sprintf("field%d", i)] = OpenAPISchema{Type: "string", Description: "A field."}
+		}
+		return obj
+	}
+
+	spec := OpenAPISchema{Type: "object", Properties: map[string]OpenAPISchema{}}
+	for count := 0; count < n; {
+		group := OpenAPISchema{Type: "object", Properties: map[string]OpenAPISchema{}}
+		for j := 0; j < 10 && count < n; j++ {
+			group.Properties[fmt.Sprintf("object%d", j)] = leaf()
+			count += 11
+		}
+		spec.Properties[fmt.Sprintf("group%d", len(spec.Properties))] = group
+		count++
+	}
+
+	return OpenAPISchema{Type: "object", Properties: map[string]OpenAPISchema{"spec": spec}}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	version := XRDVersion{Name: "v1", Served: true}
+	version.Schema.OpenAPIV3Schema = largeSchema(5000)
+	xrd := testXRD(version)
+	opts := Options{ShowNested: true, ExampleMode: "none"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := New().Generate(xrd, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFormatTypeNestedCollections(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{"{type: array, items: {type: array, items: {type: string}}}", "list(list(string))"},
+		{"{type: object, additionalProperties: {type: object, properties: {cidr: {type: string}}}}", "map[string]object"},
+		{"{type: object, additionalProperties: {type: array, items: {type: integer}}}", "map[string]list(integer)"},
+		{"{type: array, items: {type: object, additionalProperties: {type: string}}}", "list(map[string]string)"},
+	}
+	for _, tt := range tests {
+		if got := New().formatType(testSchema(t, tt.schema)); got != tt.want {
+			t.Errorf("formatType(%s) = %q, want %q", tt.schema, got, tt.want)
+		}
+	}
+}
+
+func TestExtractFieldsNestedCollections(t *testing.T) {
+	version := testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      matrix:
+        type: array
+        items:
+          type: array
+          items: {type: string}
+      networks:
+        type: object
+        additionalProperties:
+          type: object
+          properties:
+            cidr: {type: string}
+`)
+
+	spec, _ := New().ExtractFields(&version, Options{ShowNested: true})
+	got := map[string]string{}
+	for _, f := range New().flattenFields(spec) {
+		got[f.Path] = f.Type
+	}
+	want := map[string]string{
+		"spec.matrix":          "list(list(string))",
+		"spec.networks":        "map[string]object",
+		"spec.networks.*.cidr": "string",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("field types = %v, want %v", got, want)
+	}
+}
+
+func TestFormatConstraintsExclusiveBounds(t *testing.T) {
+	g := New()
+	schema := testSchema(t, "{type: integer, minimum: 1024, exclusiveMinimum: true, maximum: 65535, exclusiveMaximum: true}")
+
+	if got, want := g.formatConstraints(schema), "> 1024, < 65535"; got != want {
+		t.Errorf("formatConstraints() = %q, want %q", got, want)
+	}
+	if got := g.formatType(schema); got != "integer" {
+		t.Errorf("formatType() = %q, want integer", got)
+	}
+
+	inclusive := testSchema(t, "{type: number, minimum: 0.5, maximum: 100}")
+	if got, want := g.formatConstraints(inclusive), "Min: 0.5, Max: 100"; got != want {
+		t.Errorf("formatConstraints() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatDefault(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{"{type: string, default: eu-west-1}", "eu-west-1"},
+		{"{type: integer, default: 3}", "3"},
+		{"{type: boolean, default: false}", "false"},
+		{"{type: object, default: {replicas: 3, name: db}}", `{"name":"db","replicas":3}`},
+		{"{type: array, default: [a, b]}", `["a","b"]`},
+		{"{type: object, default: {tier: {size: small, zones: [a, b]}}}", `{"tier":{"size":"small","zones":["a","b"]}}`},
+		{"{type: array, default: [{name: a}, {name: b}]}", `[{"name":"a"},{"name":"b"}]`},
+		{"{type: object, default: {}}", "{}"},
+		{"type: string", ""},
+	}
+	for _, tt := range tests {
+		if got := New().formatDefault(testSchema(t, tt.schema).Default); got != tt.want {
+			t.Errorf("formatDefault(%s) = %q, want %q", tt.schema, got, tt.want)
+		}
+	}
+}
+
+func TestFormatConstraintsItems(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{"{type: array, items: {type: string, enum: [a, b]}}", "Items: Allowed: `a`, `b`"},
+		{"{type: array, minItems: 1, items: {type: integer, minimum: 1, maximum: 9}}", "MinItems: 1, Items: Min: 1, Max: 9"},
+		{"{type: array, items: {type: string}}", ""},
+	}
+	for _, tt := range tests {
+		schema := testSchema(t, tt.schema)
+		if got := New().formatConstraints(schema); got != tt.want {
+			t.Errorf("formatConstraints(%s) = %q, want %q", tt.schema, got, tt.want)
+		}
+	}
+
+	if got := New().formatType(testSchema(t, tests[0].schema)); got != "list(string)" {
+		t.Errorf("formatType() = %q, want list(string)", got)
+	}
+}
+
+const anchoredXRD = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xtests.example.org
+spec:
+  group: example.org
+  names: {kind: XTest, plural: xtests}
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema: &schema
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              primary: &subnet
+                type: object
+                required: [cidr]
+                properties: &subnetProperties
+                  cidr: {type: string, description: CIDR block}
+                  zone: {type: string, enum: [a, b]}
+              subnets:
+                type: array
+                items: *subnet
+              peering:
+                type: object
+                properties:
+                  <<: *subnetProperties
+                  zone: {type: integer}
+                  network: {type: string}
+  - name: v2
+    served: true
+    schema:
+      openAPIV3Schema: *schema
+`
+
+func TestParseResolvesAnchors(t *testing.T) {
+	xrd, err := Parse([]byte(anchoredXRD))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	version := xrd.Spec.Versions[0]
+	spec, _ := New().ExtractFields(&version, Options{ShowNested: true})
+	got := map[string]string{}
+	for _, f := range New().flattenFields(spec) {
+		got[f.Path] = f.Type
+	}
+	want := map[string]string{
+		"spec.primary":         "object",
+		"spec.primary.cidr":    "string",
+		"spec.primary.zone":    "string",
+		"spec.subnets":         "list(object)",
+		"spec.subnets[].cidr":  "[] string",
+		"spec.subnets[].zone":  "[] string",
+		"spec.peering":         "object",
+		"spec.peering.cidr":    "string",
+		"spec.peering.zone":    "integer",
+		"spec.peering.network": "string",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("field types = %v, want %v", got, want)
+	}
+
+	items := version.Schema.OpenAPIV3Schema.Properties["spec"].Properties["subnets"].Items
+	if items == nil || !reflect.DeepEqual(items.Required, []string{"cidr"}) {
+		t.Errorf("aliased items schema = %+v, want the anchored subnet schema", items)
+	}
+	if !reflect.DeepEqual(xrd.Spec.Versions[1].Schema, version.Schema) {
+		t.Errorf("version aliasing the whole schema differs from the anchored one")
+	}
+}
+
+func TestExcludeFieldTypesOnListItems(t *testing.T) {
+	version := testVersion(t, "v1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      subnets:
+        type: array
+        items:
+          type: object
+          properties:
+            cidr: {type: string}
+            routing:
+              type: object
+              properties:
+                table: {type: string}
+`)
+
+	spec, _ := New().ExtractFields(&version, Options{ShowNested: true})
+	var got []string
+	for _, f := range excludeFieldTypes(New().flattenFields(spec), []string{"object"}) {
+		got = append(got, f.Path)
+	}
+	want := []string{"spec.subnets", "spec.subnets[].cidr", "spec.subnets[].routing.table"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields after excluding object = %q, want %q", got, want)
+	}
+}
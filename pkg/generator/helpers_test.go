@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// testSchema parses an OpenAPI v3 schema written as YAML
+func testSchema(t testing.TB, src string) OpenAPISchema {
+	t.Helper()
+	var schema OpenAPISchema
+	if err := yaml.Unmarshal([]byte(src), &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	return schema
+}
+
+// testVersion returns a served version with the given openAPIV3Schema
+func testVersion(t testing.TB, name, schema string) XRDVersion {
+	t.Helper()
+	v := XRDVersion{Name: name, Served: true}
+	v.Schema.OpenAPIV3Schema = testSchema(t, schema)
+	return v
+}
+
+// testXRD returns an XRD of the given versions, the first being referenceable
+func testXRD(versions ...XRDVersion) *XRD {
+	xrd := &XRD{APIVersion: "apiextensions.crossplane.io/v1", Kind: KindXRD}
+	xrd.Metadata.Name = "xtests.example.org"
+	xrd.Spec.Group = "example.org"
+	xrd.Spec.Names = XRDNames{Kind: "XTest", Plural: "xtests"}
+	if len(versions) > 0 {
+		versions[0].Referenceable = true
+	}
+	xrd.Spec.Versions = versions
+	return xrd
+}
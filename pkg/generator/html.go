@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// htmlCodeSpanPattern matches markdown code spans, also double-backtick ones
+	htmlCodeSpanPattern = regexp.MustCompile("`` (.+?) ``|`([^`]+)`")
+	// htmlLinkPattern matches the markdown links to field anchors
+	htmlLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\(#([^)\s]+)\)`)
+)
+
+// htmlTemplate renders the XRD document as an HTML fragment. Tables carry
+// xrd-spec and xrd-status classes and rows an xrd-level-N class for styling.
+const htmlTemplate = `<div class="xrd">
+<h1>{{ .Title }}</h1>
+{{ with .Version.Schema.OpenAPIV3Schema.Description }}<p class="xrd-description">{{ . }}</p>
+{{ end }}{{ with .SpecCounts }}{{ if .TopLevel }}<p class="xrd-counts">Spec fields: {{ .TopLevel }} ({{ .TopLevelRequired }} required){{ if gt .Total .TopLevel }}, {{ .Total }} including nested fields ({{ .TotalRequired }} required){{ end }}</p>
+{{ end }}{{ end }}{{ if not .HideMetadata }}<dl class="xrd-metadata">
+<dt>API Group</dt><dd>{{ .XRD.Spec.Group }}</dd>
+<dt>API Version</dt><dd>{{ .Version.Name }}</dd>
+<dt>Kind</dt><dd>{{ .XRD.Spec.Names.Kind }}</dd>
+{{ with .XRD.EffectiveScope }}<dt>Scope</dt><dd>{{ . }}</dd>
+{{ end }}{{ if .XRD.OffersClaims }}<dt>Claim Kind</dt><dd>{{ .XRD.Spec.ClaimNames.Kind }}</dd>
+{{ end }}{{ with .XRD.Metadata.Name }}<dt>{{ if $.XRD.IsCRD }}CRD{{ else }}XRD{{ end }} Name</dt><dd><code>{{ . }}</code></dd>
+{{ end }}{{ range .Labels }}<dt>Label <code>{{ .Key }}</code></dt><dd>{{ .Value }}</dd>
+{{ end }}{{ range .Annotations }}<dt>Annotation <code>{{ .Key }}</code></dt><dd>{{ .Value }}</dd>
+{{ end }}</dl>
+{{ end }}
+<h2>Spec Fields</h2>
+<table class="xrd-spec">
+<thead>
+<tr><th>Name</th><th>Type</th><th>Description</th><th>Required</th><th>Default</th>{{ if .Effective }}<th>Effective Default</th>{{ end }}{{ if .ShowExamples }}<th>Example</th>{{ end }}<th>Constraints</th></tr>
+</thead>
+<tbody>
+{{ range .SpecFields -}}
+<tr class="xrd-level-{{ .Level }}"{{ if .Anchor }} id="{{ .Anchor }}"{{ end }}><td class="xrd-name">{{ template "name" (fieldName . $.FullPaths) }}</td><td>{{ .Type }}</td><td>{{ inline .Description }}</td><td>{{ if .Required }}✅{{ else }}❌{{ end }}</td><td>{{ if .Default }}<code>{{ .Default }}</code>{{ else }}-{{ end }}</td>{{ if $.Effective }}<td>{{ if .Effective }}{{ .Effective }}{{ else }}-{{ end }}</td>{{ end }}{{ if $.ShowExamples }}<td>{{ if .Example }}<code>{{ .Example }}</code>{{ else }}-{{ end }}</td>{{ end }}<td>{{ if .Constraints }}{{ inline .Constraints }}{{ else }}-{{ end }}</td></tr>
+{{ end -}}
+</tbody>
+</table>
+{{ if .Effective }}<p class="xrd-effective-note">The effective default is what an unset field ends up with: the XRD default, otherwise the base value a bundled composition leaves at the patch target. Only base values are considered; transforms never supply one, as Crossplane skips a patch whose source field is unset.</p>
+{{ end }}{{ if and .StatusFields (not .HideStatus) }}
+<h2>Status Fields</h2>
+<table class="xrd-status">
+<thead>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{ range .StatusFields -}}
+<tr class="xrd-level-{{ .Level }}"{{ if .Anchor }} id="{{ .Anchor }}"{{ end }}><td class="xrd-name">{{ template "name" (fieldName . $.FullPaths) }}</td><td>{{ .Type }}</td><td>{{ inline .Description }}</td></tr>
+{{ end -}}
+</tbody>
+</table>
+{{ end }}{{ if not .HideExample }}
+<h2>Example</h2>
+{{ with .ExampleTarget }}<p>This example creates a {{ .Resource }} (<code>{{ .Kind }}</code>), which {{ if .Namespaced }}lives in a namespace{{ else }}is cluster-scoped{{ end }}:</p>
+{{ end }}<pre class="xrd-example"><code class="language-yaml">{{ .Example }}</code></pre>
+{{ end }}</div>
+{{ define "name" }}{{ if .Nested }}<span class="xrd-nested">↳</span> {{ end }}{{ if .Deprecated }}<del>{{ .Text }}</del> <span class="xrd-deprecated">⚠️ Deprecated</span>{{ else }}{{ .Text }}{{ end }}{{ end }}`
+
+// htmlFieldName is the text of a field's name cell
+type htmlFieldName struct {
+	Text       string
+	Nested     bool
+	Deprecated bool
+}
+
+// writeHTML writes the same document data as the markdown output as an HTML
+// fragment; html/template escapes every value
+func (g *Generator) writeHTML(w io.Writer, xrd *XRD, version *XRDVersion, specFields, statusFields []Field, removed []RemovedFields, opts Options) error {
+	funcMap := template.FuncMap{
+		"inline": inlineHTML,
+		"fieldName": func(f Field, fullPaths bool) htmlFieldName {
+			if fullPaths {
+				return htmlFieldName{Text: f.Path, Deprecated: f.Deprecated}
+			}
+			return htmlFieldName{Text: f.Name, Nested: f.Level > 0, Deprecated: f.Deprecated}
+		},
+	}
+
+	t, err := template.New("html").Funcs(funcMap).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	data, err := g.templateData(xrd, version, specFields, statusFields, removed, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
+
+// inlineHTML converts the inline markdown of a table cell, code spans and field
+// links, to HTML. Everything else is escaped.
+func inlineHTML(s string) template.HTML {
+	s = template.HTMLEscapeString(strings.ReplaceAll(s, `\|`, "|"))
+	s = htmlCodeSpanPattern.ReplaceAllStringFunc(s, func(span string) string {
+		m := htmlCodeSpanPattern.FindStringSubmatch(span)
+		return "<code>" + m[1] + m[2] + "</code>"
+	})
+	s = htmlLinkPattern.ReplaceAllString(s, `<a href="#$2">$1</a>`)
+	return template.HTML(s)
+}
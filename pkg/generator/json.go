@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Document is the structured form of an XRD's documentation, rendered by the json format
+type Document struct {
+	Kind        string        `json:"kind"`
+	Group       string        `json:"group"`
+	ClaimKind   string        `json:"claimKind,omitempty"`
+	Scope       string        `json:"scope,omitempty"`
+	Version     string        `json:"version"` // Documented version
+	Versions    []VersionInfo `json:"versions"`
+	Description string        `json:"description,omitempty"`
+	Spec        []FieldDoc    `json:"spec"`
+	Status      []FieldDoc    `json:"status"`
+}
+
+// VersionInfo describes one version of the XRD
+type VersionInfo struct {
+	Name          string `json:"name"`
+	Served        bool   `json:"served"`
+	Referenceable bool   `json:"referenceable"`
+}
+
+// FieldDoc is a documented field with its schema values kept typed
+type FieldDoc struct {
+	Name        string       `json:"name"`
+	Path        string       `json:"path"`
+	Type        string       `json:"type"`
+	Description string       `json:"description,omitempty"`
+	Required    bool         `json:"required"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+	Default     interface{}  `json:"default,omitempty"`
+	Example     interface{}  `json:"example,omitempty"`
+	Constraints *Constraints `json:"constraints,omitempty"`
+	Effective   string       `json:"effectiveDefault,omitempty"` // Set in bundle mode
+	Fields      []FieldDoc   `json:"fields,omitempty"`
+}
+
+// Constraints are the validation constraints of a field
+type Constraints struct {
+	Enum    []interface{} `json:"enum,omitempty"`
+	Minimum *float64      `json:"minimum,omitempty"`
+	Maximum *float64      `json:"maximum,omitempty"`
+
+	ExclusiveMinimum bool `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool `json:"exclusiveMaximum,omitempty"`
+
+	MinItems  *int    `json:"minItems,omitempty"`
+	MaxItems  *int    `json:"maxItems,omitempty"`
+	MinLength *int    `json:"minLength,omitempty"`
+	MaxLength *int    `json:"maxLength,omitempty"`
+	Pattern   *string `json:"pattern,omitempty"`
+	Format    string  `json:"format,omitempty"`
+}
+
+// generateJSON renders the documented version and its field trees as indented JSON
+func (g *Generator) generateJSON(xrd *XRD, version *XRDVersion, specFields, statusFields []Field) (string, error) {
+	doc := Document{
+		Kind:        xrd.Spec.Names.Kind,
+		Group:       xrd.Spec.Group,
+		Scope:       xrd.EffectiveScope(),
+		Version:     version.Name,
+		Versions:    []VersionInfo{},
+		Description: version.Schema.OpenAPIV3Schema.Description,
+		Spec:        fieldDocs(specFields),
+		Status:      fieldDocs(statusFields),
+	}
+	if xrd.OffersClaims() {
+		doc.ClaimKind = xrd.Spec.ClaimNames.Kind
+	}
+	for _, v := range xrd.Spec.Versions {
+		doc.Versions = append(doc.Versions, VersionInfo{Name: v.Name, Served: v.Served, Referenceable: v.Referenceable})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// fieldDocs converts a field tree, keeping the nesting
+func fieldDocs(fields []Field) []FieldDoc {
+	docs := make([]FieldDoc, 0, len(fields))
+	for _, f := range fields {
+		docs = append(docs, FieldDoc{
+			Name:        f.Name,
+			Path:        f.Path,
+			Type:        f.Type,
+			Description: f.Description,
+			Required:    f.Required,
+			Deprecated:  f.Deprecated,
+			Default:     f.schema.Default,
+			Example:     f.schema.Example,
+			Constraints: constraintsOf(f.schema),
+			Effective:   f.Effective,
+			Fields:      fieldDocsOrNil(f.Nested),
+		})
+	}
+	return docs
+}
+
+// fieldDocsOrNil converts nested fields, returning nil for leaves so they're omitted
+func fieldDocsOrNil(fields []Field) []FieldDoc {
+	if len(fields) == 0 {
+		return nil
+	}
+	return fieldDocs(fields)
+}
+
+// constraintsOf returns the schema's constraints, or nil if it has none
+func constraintsOf(schema OpenAPISchema) *Constraints {
+	c := Constraints{
+		Enum:    schema.Enum,
+		Minimum: schema.Minimum,
+		Maximum: schema.Maximum,
+
+		ExclusiveMinimum: schema.ExclusiveMinimum,
+		ExclusiveMaximum: schema.ExclusiveMaximum,
+
+		MinItems:  schema.MinItems,
+		MaxItems:  schema.MaxItems,
+		MinLength: schema.MinLength,
+		MaxLength: schema.MaxLength,
+		Pattern:   schema.Pattern,
+		Format:    schema.Format,
+	}
+	if c.Enum == nil && c.Minimum == nil && c.Maximum == nil && c.MinItems == nil && c.MaxItems == nil &&
+		c.MinLength == nil && c.MaxLength == nil && c.Pattern == nil && c.Format == "" {
+		return nil
+	}
+	return &c
+}
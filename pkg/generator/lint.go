@@ -0,0 +1,72 @@
+package generator
+
+import "strings"
+
+// UnconstrainedFields returns the full paths of the scalar spec fields, nested ones
+// and those on list items and map values included, that carry no validation at all
+// in the version selected by opts: not required, and no enum, bounds or other constraint
+func (g *Generator) UnconstrainedFields(xrd *XRD, opts Options) ([]string, error) {
+	if len(xrd.Spec.Versions) == 0 {
+		return nil, nil
+	}
+	index, err := xrd.versionIndex(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	version := &xrd.Spec.Versions[index]
+
+	opts.ShowNested = true
+	specFields := g.extractFields(version.Schema.OpenAPIV3Schema, "spec", []string{}, 0, opts)
+
+	var paths []string
+	for _, field := range g.flattenFields(specFields) {
+		if field.Required {
+			continue
+		}
+		// Lists and maps of scalars are constrained through their items and values
+		schema, fieldPath := field.schema, field.Path
+		switch values, isMap := schema.mapValues(); {
+		case schema.Type == "array" && schema.Items != nil:
+			schema, fieldPath = *schema.Items, fieldPath+"[]"
+		case isMap && len(schema.Properties) == 0:
+			schema, fieldPath = *values, fieldPath+".*"
+		}
+		if g.unconstrainedScalar(schema) {
+			paths = append(paths, fieldPath)
+		}
+	}
+	return paths, nil
+}
+
+// unconstrainedScalar reports whether schema is a scalar without any validation
+func (g *Generator) unconstrainedScalar(schema OpenAPISchema) bool {
+	switch schema.Type {
+	case "string", "integer", "number":
+		return g.formatConstraints(schema) == ""
+	}
+	return false
+}
+
+// UndocumentedFields returns the full paths of the spec fields, nested ones
+// included, that have no description in the version selected by opts
+func (g *Generator) UndocumentedFields(xrd *XRD, opts Options) ([]string, error) {
+	if len(xrd.Spec.Versions) == 0 {
+		return nil, nil
+	}
+	index, err := xrd.versionIndex(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	version := &xrd.Spec.Versions[index]
+
+	opts.ShowNested = true
+	specFields := g.extractFields(version.Schema.OpenAPIV3Schema, "spec", []string{}, 0, opts)
+
+	var paths []string
+	for _, field := range g.flattenFields(specFields) {
+		if strings.TrimSpace(field.Description) == "" {
+			paths = append(paths, field.Path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+const lintSchema = `
+type: object
+properties:
+  spec:
+    type: object
+    required: [name]
+    properties:
+      name: {type: string}
+      region: {type: string}
+      size: {type: string, enum: [small, large]}
+      subnets:
+        type: array
+        items:
+          type: object
+          required: [cidr]
+          properties:
+            cidr: {type: string}
+            zone: {type: string}
+            weight: {type: integer, minimum: 1}
+      networks:
+        type: object
+        additionalProperties:
+          type: object
+          properties:
+            cidr: {type: string}
+      labels:
+        type: object
+        additionalProperties: {type: string}
+      ports:
+        type: array
+        items: {type: integer, maximum: 65535}
+`
+
+func TestUnconstrainedFields(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", lintSchema))
+
+	got, err := New().UnconstrainedFields(xrd, Options{})
+	if err != nil {
+		t.Fatalf("UnconstrainedFields() error = %v", err)
+	}
+	want := []string{"spec.labels.*", "spec.networks.*.cidr", "spec.region", "spec.subnets[].zone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnconstrainedFields() = %q, want %q", got, want)
+	}
+}
+
+func TestUnconstrainedFieldsOfSelectedVersion(t *testing.T) {
+	xrd := testXRD(
+		testVersion(t, "v1", "{type: object, properties: {spec: {type: object, properties: {size: {type: string, enum: [a]}}}}}"),
+		testVersion(t, "v2", "{type: object, properties: {spec: {type: object, properties: {size: {type: string}}}}}"),
+	)
+
+	for version, want := range map[string][]string{"": nil, "v1": nil, "v2": {"spec.size"}} {
+		got, err := New().UnconstrainedFields(xrd, Options{Version: version})
+		if err != nil {
+			t.Fatalf("UnconstrainedFields(%q) error = %v", version, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("UnconstrainedFields(%q) = %q, want %q", version, got, want)
+		}
+	}
+
+	if _, err := New().UnconstrainedFields(xrd, Options{Version: "v3"}); err == nil {
+		t.Error("UnconstrainedFields() of an unknown version succeeded")
+	}
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+)
+
+// VersionMatrix compares the fields of all served versions
+type VersionMatrix struct {
+	Versions []string
+	Rows     []VersionMatrixRow
+}
+
+// VersionMatrixRow represents a field path and its presence in each version
+type VersionMatrixRow struct {
+	Path  string
+	Cells []string // ✓ present, R required, – absent; one per version
+	Notes string   // e.g. type changes across versions
+}
+
+// matrixField is the per-version information the matrix and Diff compare
+type matrixField struct {
+	Type        string
+	Required    bool
+	Constraints string
+}
+
+// buildVersionMatrix builds a matrix of the union of spec and status field paths
+// across the served versions
+func (g *Generator) buildVersionMatrix(xrd *XRD) VersionMatrix {
+	var matrix VersionMatrix
+
+	var perVersion []map[string]matrixField
+	all := make(map[string]bool)
+	for _, v := range xrd.Spec.Versions {
+		if !v.Served {
+			continue
+		}
+		fields := make(map[string]matrixField)
+		g.collectMatrixFields(v.Schema.OpenAPIV3Schema, "", fields)
+
+		matrix.Versions = append(matrix.Versions, v.Name)
+		perVersion = append(perVersion, fields)
+		for path := range fields {
+			all[path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(all))
+	for path := range all {
+		if strings.HasPrefix(path, "spec.") || strings.HasPrefix(path, "status.") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		row := VersionMatrixRow{Path: path}
+		var types []string
+		lastType := ""
+		for i, fields := range perVersion {
+			f, ok := fields[path]
+			switch {
+			case !ok:
+				row.Cells = append(row.Cells, "–")
+				continue
+			case f.Required:
+				row.Cells = append(row.Cells, "R")
+			default:
+				row.Cells = append(row.Cells, "✓")
+			}
+			// Compare the bare types; the notes label each with its version
+			if len(types) == 0 || lastType != f.Type {
+				types = append(types, matrix.Versions[i]+": "+f.Type)
+				lastType = f.Type
+			}
+		}
+		if len(types) > 1 {
+			row.Notes = "⚠️ type changes (" + strings.Join(types, ", ") + ")"
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+
+	return matrix
+}
+
+// collectMatrixFields records the type, requiredness and constraints of every property in the schema
+func (g *Generator) collectMatrixFields(schema OpenAPISchema, prefix string, fields map[string]matrixField) {
+	for name, prop := range schema.Properties {
+		path := joinPath(prefix, name)
+		fields[path] = matrixField{
+			Type:        g.formatType(prop),
+			Required:    contains(schema.Required, name),
+			Constraints: g.formatConstraints(prop),
+		}
+		g.collectNestedMatrixFields(prop, path, fields)
+	}
+}
+
+// collectNestedMatrixFields records the fields below a field: its properties, the
+// fields of its list items (path[]) and of its map values (path.*), like the field tables
+func (g *Generator) collectNestedMatrixFields(schema OpenAPISchema, path string, fields map[string]matrixField) {
+	g.collectMatrixFields(schema, path, fields)
+	if schema.Items != nil {
+		g.collectNestedMatrixFields(*schema.Items, path+"[]", fields)
+	}
+	if values, ok := schema.mapValues(); ok {
+		g.collectNestedMatrixFields(*values, path+".*", fields)
+	}
+}
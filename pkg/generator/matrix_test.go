@@ -0,0 +1,74 @@
+package generator
+
+import "testing"
+
+func TestVersionMatrix(t *testing.T) {
+	xrd := testXRD(
+		testVersion(t, "v1alpha1", `
+type: object
+properties:
+  spec:
+    type: object
+    properties:
+      region:
+        type: string
+      size:
+        type: string
+      subnets:
+        type: array
+        items:
+          type: object
+          properties:
+            cidr:
+              type: string
+`),
+		testVersion(t, "v1beta1", `
+type: object
+properties:
+  spec:
+    type: object
+    required: [region]
+    properties:
+      region:
+        type: string
+      size:
+        type: integer
+      subnets:
+        type: array
+        items:
+          type: object
+          properties:
+            cidr:
+              type: string
+`),
+	)
+
+	rows := make(map[string]VersionMatrixRow)
+	for _, row := range New().buildVersionMatrix(xrd).Rows {
+		rows[row.Path] = row
+	}
+
+	tests := []struct {
+		path  string
+		cells []string
+		notes string
+	}{
+		{path: "spec.region", cells: []string{"✓", "R"}},
+		{path: "spec.size", cells: []string{"✓", "✓"}, notes: "⚠️ type changes (v1alpha1: string, v1beta1: integer)"},
+		{path: "spec.subnets", cells: []string{"✓", "✓"}},
+		{path: "spec.subnets[].cidr", cells: []string{"✓", "✓"}},
+	}
+	for _, tt := range tests {
+		row, ok := rows[tt.path]
+		if !ok {
+			t.Errorf("%s: missing from the matrix", tt.path)
+			continue
+		}
+		if len(row.Cells) != len(tt.cells) || row.Cells[0] != tt.cells[0] || row.Cells[1] != tt.cells[1] {
+			t.Errorf("%s: cells = %q, want %q", tt.path, row.Cells, tt.cells)
+		}
+		if row.Notes != tt.notes {
+			t.Errorf("%s: notes = %q, want %q", tt.path, row.Notes, tt.notes)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+)
+
+// Metadata is a compact summary of an XRD for search and index builders.
+// Its JSON shape is stable: fields are only ever added, never renamed or removed.
+type Metadata struct {
+	Kind        string   `json:"kind"`                 // Composite resource kind
+	ClaimKind   string   `json:"claimKind,omitempty"`  // Claim kind, if the XRD offers claims
+	Group       string   `json:"group"`                // API group
+	Versions    []string `json:"versions"`             // Served version names
+	FieldCount  int      `json:"fieldCount"`           // Number of spec fields, including nested fields
+	Description string   `json:"description"`          // First paragraph of the schema description
+	Categories  []string `json:"categories,omitempty"` // kubectl categories
+}
+
+// Metadata summarizes the XRD for its documented version
+func (g *Generator) Metadata(xrd *XRD, opts Options) Metadata {
+	meta := Metadata{
+		Kind:       xrd.Spec.Names.Kind,
+		Group:      xrd.Spec.Group,
+		Versions:   []string{},
+		Categories: xrd.Spec.Names.Categories,
+	}
+	if xrd.OffersClaims() {
+		meta.ClaimKind = xrd.Spec.ClaimNames.Kind
+	}
+
+	for _, v := range xrd.Spec.Versions {
+		if v.Served {
+			meta.Versions = append(meta.Versions, v.Name)
+		}
+	}
+
+	index, err := xrd.versionIndex(opts.Version)
+	if err != nil || len(xrd.Spec.Versions) == 0 {
+		return meta
+	}
+	version := &xrd.Spec.Versions[index]
+
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(version.Schema.OpenAPIV3Schema.Description), "\n\n")
+	meta.Description = strings.Join(strings.Fields(paragraph), " ")
+
+	specFields := g.extractFields(version.Schema.OpenAPIV3Schema, "spec", []string{}, 0, opts)
+	meta.FieldCount = len(g.flattenFields(specFields))
+
+	return meta
+}
+
+// MetadataEntry is a label or annotation of the XRD shown in its docs
+type MetadataEntry struct {
+	Key   string
+	Value string
+}
+
+// selectMetadata returns the entries whose keys start with one of prefixes,
+// sorted by key. Values are collapsed onto one line.
+func selectMetadata(entries map[string]string, prefixes []string) []MetadataEntry {
+	var selected []MetadataEntry
+	for key, value := range entries {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				selected = append(selected, MetadataEntry{Key: key, Value: strings.Join(strings.Fields(value), " ")})
+				break
+			}
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Key < selected[j].Key
+	})
+	return selected
+}
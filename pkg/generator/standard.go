@@ -0,0 +1,47 @@
+package generator
+
+// standardStatusFields are the status fields Crossplane adds to every composite
+// resource and claim, whether or not the XRD's schema declares them
+var standardStatusFields = []Field{
+	{
+		Name:        "conditions",
+		Path:        "status.conditions",
+		Type:        "list(object)",
+		Description: "Conditions of the resource, such as `Ready` and `Synced` (added by Crossplane)",
+		Nested: []Field{
+			{Name: "type", Path: "status.conditions[].type", Type: "[] string", Description: "Condition type, e.g. `Ready`", Level: 1},
+			{Name: "status", Path: "status.conditions[].status", Type: "[] string", Description: "`True`, `False` or `Unknown`", Level: 1},
+			{Name: "reason", Path: "status.conditions[].reason", Type: "[] string", Description: "Machine-readable reason for the last transition", Level: 1},
+			{Name: "message", Path: "status.conditions[].message", Type: "[] string", Description: "Human-readable details of the last transition", Level: 1},
+			{Name: "lastTransitionTime", Path: "status.conditions[].lastTransitionTime", Type: "[] string (date-time)", Description: "When the condition last changed", Level: 1},
+		},
+	},
+	{
+		Name:        "connectionDetails",
+		Path:        "status.connectionDetails",
+		Type:        "object",
+		Description: "Connection secret publishing status (added by Crossplane)",
+		Nested: []Field{
+			{Name: "lastPublishedTime", Path: "status.connectionDetails.lastPublishedTime", Type: "string (date-time)", Description: "When the connection details were last published", Level: 1},
+		},
+	},
+}
+
+// withStandardStatus adds the Crossplane status fields the schema doesn't declare
+func withStandardStatus(fields []Field, opts Options) []Field {
+	declared := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		declared[f.Name] = true
+	}
+
+	for _, f := range standardStatusFields {
+		if declared[f.Name] {
+			continue
+		}
+		if !opts.ShowNested {
+			f.Nested = nil
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
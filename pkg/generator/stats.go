@@ -0,0 +1,50 @@
+package generator
+
+import "fmt"
+
+// Statistics summarizes the API surface of an XRD's documented version
+type Statistics struct {
+	Kind           string `json:"kind"`
+	Version        string `json:"version"`
+	SpecFields     int    `json:"specFields"`     // Spec fields, including nested fields
+	RequiredFields int    `json:"requiredFields"` // Spec fields required by their parent
+	StatusFields   int    `json:"statusFields"`   // Status fields, including nested fields
+	Enums          int    `json:"enums"`          // Spec and status fields restricted to an enum
+	MaxDepth       int    `json:"maxDepth"`       // Deepest nesting below spec or status, top-level fields are 1
+	CELRules       int    `json:"celRules"`       // x-kubernetes-validations rules, including the root's
+}
+
+// Stats counts the fields, constraints and CEL rules of the XRD's documented version
+func (g *Generator) Stats(xrd *XRD) (Statistics, error) {
+	version := xrd.DefaultVersion()
+	if version == nil {
+		return Statistics{}, fmt.Errorf("no versions found in XRD")
+	}
+
+	opts := Options{ShowNested: true}
+	specFields, statusFields := g.ExtractFields(version, opts)
+	spec, status := g.flattenFields(specFields), g.flattenFields(statusFields)
+
+	stats := Statistics{
+		Kind:         xrd.Spec.Names.Kind,
+		Version:      version.Name,
+		SpecFields:   len(spec),
+		StatusFields: len(status),
+		CELRules:     len(validationRulesOf(version, opts)),
+	}
+	for _, f := range spec {
+		if f.Required {
+			stats.RequiredFields++
+		}
+	}
+	for _, fields := range [][]Field{spec, status} {
+		for _, f := range fields {
+			if len(f.schema.Enum) > 0 {
+				stats.Enums++
+			}
+			stats.MaxDepth = max(stats.MaxDepth, f.Level+1)
+		}
+	}
+
+	return stats, nil
+}
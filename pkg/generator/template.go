@@ -0,0 +1,191 @@
+package generator
+
+// xrdSections are the sections of an XRD document in render order
+var xrdSections = []string{"header", "metadata", "removed", "matrix", "quickstart", "spec", "claims", "validation", "status", "columns", "example"}
+
+// consumerSections are the sections of the concise document for claim consumers
+var consumerSections = []string{"header", "required", "readiness", "connection"}
+
+// xrdTemplate defines each section of an XRD document as a named template.
+// Sections are executed one by one, so text between the definitions is ignored.
+const xrdTemplate = `{{ define "header" }}# {{ .Title }}
+{{ with .Version.Schema.OpenAPIV3Schema.Description }}
+{{ if $.TechDocs }}!!! note
+{{ indentBlock . }}{{ else }}{{ . }}{{ end }}
+{{ end }}{{ if and .SpecCounts.TopLevel (not .AllVersions) }}{{ with .SpecCounts }}
+**Spec fields:** {{ .TopLevel }} ({{ .TopLevelRequired }} required){{ if gt .Total .TopLevel }}, {{ .Total }} including nested fields ({{ .TotalRequired }} required){{ end }}
+{{ end }}{{ end }}{{ end }}
+
+{{ define "metadata" }}{{ if not .HideMetadata }}
+**API Group:** {{ .XRD.Spec.Group }}  
+{{ if .AllVersions }}**API Versions:** {{ range $i, $v := .XRD.Spec.Versions }}{{ if $i }}, {{ end }}` + "`{{ $v.Name }}`" + `{{ end }}  
+{{ else }}**API Version:** {{ .Version.Name }}  
+{{ end }}**Kind:** {{ .XRD.Spec.Names.Kind }}  
+{{ with .XRD.Spec.Names.ShortNames }}**Short Names:** {{ codeList . }}  
+{{ end }}{{ with .XRD.Spec.Names.Categories }}**Categories:** {{ codeList . }}  
+{{ end }}{{ with .XRD.EffectiveScope }}**Scope:** {{ . }}  
+{{ end }}{{ if .XRD.OffersClaims }}{{ with .XRD.Spec.ClaimNames }}**Claim Kind:** {{ .Kind }}  
+{{ with .ShortNames }}**Claim Short Names:** {{ codeList . }}  
+{{ end }}{{ with .Categories }}**Claim Categories:** {{ codeList . }}  
+{{ end }}{{ end }}{{ end }}{{ with .XRD.Metadata.Name }}**{{ if $.XRD.IsCRD }}CRD{{ else }}XRD{{ end }} Name:** ` + "`{{ . }}`" + `  
+{{ end }}{{ with .Labels }}
+**Labels:**
+
+{{ range . }}- ` + "`{{ .Key }}`" + `: {{ .Value }}
+{{ end }}{{ end }}{{ with .Annotations }}
+**Annotations:**
+
+{{ range . }}- ` + "`{{ .Key }}`" + `: {{ .Value }}
+{{ end }}{{ end }}{{ end }}{{ end }}
+
+{{ define "removed" }}{{ range .Removed }}{{ if $.TechDocs }}
+!!! warning "Removed since {{ .Since }}"
+    {{ range $i, $p := .Paths }}{{ if $i }}, {{ end }}` + "`{{ $p }}`" + `{{ end }}
+{{ else }}
+> **Removed since {{ .Since }}:** {{ range $i, $p := .Paths }}{{ if $i }}, {{ end }}` + "`{{ $p }}`" + `{{ end }}
+{{ end }}{{ end }}{{ end }}
+
+{{ define "matrix" }}{{ with .Matrix }}
+## Version Matrix
+
+✓ present, R required, – absent
+
+| Field |{{ range .Versions }} {{ . }} |{{ end }} Notes |
+|-------|{{ range .Versions }}------|{{ end }}-------|
+{{ range .Rows -}}
+| {{ .Path }} |{{ range .Cells }} {{ . }} |{{ end }} {{ if .Notes }}{{ .Notes }}{{ else }}-{{ end }} |
+{{ end }}{{ end }}{{ end }}
+
+{{ define "quickstart" }}{{ with .Quickstart }}
+## Quick Start
+
+The smallest manifest you can apply. Replace the values marked ` + "`# replace`" + ` before applying it.
+
+` + "```yaml" + `
+{{ . }}` + "```" + `
+{{ end }}{{ end }}
+
+{{ define "spec" }}
+## Spec Fields
+{{ if .TechDocs }}
+!!! info "Required fields"
+    Fields marked ✅ must be set; fields marked ❌ are optional.
+{{ end }}
+| Name | Type | Description | Required | Default |{{ if $.Effective }} Effective Default |{{ end }}{{ if $.ShowExamples }} Example |{{ end }} Constraints |
+|------|------|-------------|----------|---------|{{ if $.Effective }}-------------------|{{ end }}{{ if $.ShowExamples }}---------|{{ end }}-------------|
+{{ range .SpecFields -}}
+| {{ template "field-name" (fieldCell . $.FullPaths) }} | {{ .Type }} | {{ .Description }} | {{ if .Required }}✅{{ else }}❌{{ end }} | {{ if .Default }}` + "`{{ .Default }}`" + `{{ else }}-{{ end }} |{{ if $.Effective }} {{ if .Effective }}{{ .Effective }}{{ else }}-{{ end }} |{{ end }}{{ if $.ShowExamples }} {{ if .Example }}` + "`{{ .Example }}`" + `{{ else }}-{{ end }} |{{ end }} {{ if .Constraints }}{{ .Constraints }}{{ else }}-{{ end }} |
+{{ end }}{{ template "effective-note" . }}{{ end }}
+
+{{ define "spec-compact" }}
+## Spec Fields
+{{ if .TechDocs }}
+!!! info "Required fields"
+    Fields marked ✅ must be set; fields marked ❌ are optional.
+{{ end }}
+| Name | Type | Required |
+|------|------|----------|
+{{ range .SpecFields -}}
+| {{ template "field-name" (fieldCell . $.FullPaths) }} | {{ .Type }} | {{ if .Required }}✅{{ else }}❌{{ end }} |
+{{ end }}{{ end }}
+
+{{ define "spec-wide" }}
+## Spec Fields
+{{ if .TechDocs }}
+!!! info "Required fields"
+    Fields marked ✅ must be set; fields marked ❌ are optional.
+{{ end }}
+| Name | Path | Type | Description | Required | Default |{{ if $.Effective }} Effective Default |{{ end }}{{ if $.ShowExamples }} Example |{{ end }} Constraints | Validation Rules |
+|------|------|------|-------------|----------|---------|{{ if $.Effective }}-------------------|{{ end }}{{ if $.ShowExamples }}---------|{{ end }}-------------|------------------|
+{{ range .SpecFields -}}
+| {{ template "field-name" (fieldCell . false) }} | ` + "`{{ .Path }}`" + ` | {{ .Type }} | {{ .Description }} | {{ if .Required }}✅{{ else }}❌{{ end }} | {{ if .Default }}` + "`{{ .Default }}`" + `{{ else }}-{{ end }} |{{ if $.Effective }} {{ if .Effective }}{{ .Effective }}{{ else }}-{{ end }} |{{ end }}{{ if $.ShowExamples }} {{ if .Example }}` + "`{{ .Example }}`" + `{{ else }}-{{ end }} |{{ end }} {{ if .Constraints }}{{ .Constraints }}{{ else }}-{{ end }} | {{ with fieldRules . }}{{ . }}{{ else }}-{{ end }} |
+{{ end }}{{ template "effective-note" . }}{{ end }}
+
+{{ define "effective-note" }}{{ if .Effective }}
+The effective default is what an unset field ends up with: the XRD default, otherwise the base value a bundled composition leaves at the patch target. Only base values are considered; transforms never supply one, as Crossplane skips a patch whose source field is unset.
+{{ end }}{{ end }}
+
+{{ define "field-name" }}{{ with .Field }}{{ if $.FullPaths }}{{ if .Anchor }}<a id="{{ .Anchor }}"></a>{{ end }}{{ .Path }}{{ else }}{{ if gt .Level 0 }}{{ indent .Level }}{{ end }}{{ if .Anchor }}<a id="{{ .Anchor }}"></a>{{ end }}{{ .Name }}{{ end }}{{ if .Deprecated }} ⚠️ Deprecated{{ end }}{{ end }}{{ end }}
+
+{{ define "claims" }}{{ if .XRD.OffersClaims }}
+### Claim and Composite Differences
+
+Crossplane adds a few fields to only one of the claim ({{ .XRD.Spec.ClaimNames.Kind }}) and the composite ({{ .XRD.Spec.Names.Kind }}).
+
+**Claim-only:**
+{{ range .ClaimOnly }}
+- ` + "`{{ .Path }}`" + `: {{ .Description }}{{ end }}
+
+**Composite-only:**
+{{ range .CompositeOnly }}
+- ` + "`{{ .Path }}`" + `: {{ .Description }}{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "validation" }}{{ if .ValidationRules }}
+## Validation Rules
+
+| Field | Rule | Message | Reason | Field Path |
+|-------|------|---------|--------|------------|
+{{ range .ValidationRules -}}
+| {{ .Path }} | {{ codeCell .Rule }} | {{ if .Message }}{{ cell .Message }}{{ else if .MessageExpression }}{{ codeCell .MessageExpression }}{{ else }}-{{ end }} | {{ if .Reason }}{{ .Reason }}{{ else }}-{{ end }} | {{ if .FieldPath }}` + "`{{ .FieldPath }}`" + `{{ else }}-{{ end }} |
+{{ end }}
+{{ end }}{{ end }}
+
+{{ define "status" }}{{ if and .StatusFields (not .HideStatus) }}
+## Status Fields
+
+| Name | Type | Description |
+|------|------|-------------|
+{{ range .StatusFields -}}
+| {{ template "field-name" (fieldCell . $.FullPaths) }} | {{ .Type }} | {{ .Description }} |
+{{ end }}
+{{ end }}{{ end }}
+
+{{ define "columns" }}{{ with .PrinterColumns }}
+## Printer Columns
+
+Extra columns shown by ` + "`kubectl get`" + `:
+
+| Name | Type | JSON Path | Description |
+|------|------|-----------|-------------|
+{{ range . -}}
+| {{ .Name }} | {{ .Type }} | ` + "`{{ .JSONPath }}`" + ` | {{ if .Description }}{{ cell .Description }}{{ else }}-{{ end }} |
+{{ end }}
+{{ end }}{{ end }}
+
+{{ define "example" }}{{ if not .HideExample }}
+## Example
+{{ with .ExampleTarget }}
+This example creates a {{ .Resource }} (` + "`{{ .Kind }}`" + `), which {{ if .Namespaced }}lives in a namespace{{ else }}is cluster-scoped{{ end }}:
+{{ end }}
+` + "```yaml" + `
+{{ .Example }}` + "```" + `
+{{ end }}{{ end }}
+
+{{ define "required" }}
+## What You Set
+
+{{ if .RequiredFields }}| Name | Type | Description | Default | Constraints |
+|------|------|-------------|---------|-------------|
+{{ range .RequiredFields -}}
+| {{ template "field-name" (fieldCell . $.FullPaths) }} | {{ .Type }} | {{ .Description }} | {{ if .Default }}` + "`{{ .Default }}`" + `{{ else }}-{{ end }} | {{ if .Constraints }}{{ .Constraints }}{{ else }}-{{ end }} |
+{{ end }}{{ else }}No spec fields are required.
+{{ end }}{{ end }}
+
+{{ define "readiness" }}{{ if .Compositions }}
+## When It's Ready
+{{ range .Compositions }}{{ if gt (len $.Compositions) 1 }}
+**{{ .Name }}**
+{{ end }}
+{{ range .Readiness }}- {{ . }}
+{{ end }}{{ end }}{{ end }}{{ end }}
+
+{{ define "connection" }}{{ if .ConnectionKeys }}
+## What You Get
+
+Set ` + "`spec.writeConnectionSecretToRef.name`" + ` to receive a connection secret{{ if .XRD.OffersClaims }} in the claim's namespace{{ end }} with these keys:
+
+{{ range .ConnectionKeys }}- ` + "`{{ . }}`" + `
+{{ end }}{{ end }}{{ end }}
+`
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableOfContents renders a linked list of the level 2 and 3 headings of markdown,
+// outside code blocks. Repeated headings get the numbered anchors GitHub and
+// MkDocs give them.
+func tableOfContents(markdown string, techDocs bool) string {
+	slug, suffix := githubSlugs.slugify, "-%d"
+	if techDocs {
+		slug, suffix = mkdocsSlugs.slugify, "_%d"
+	}
+
+	var b strings.Builder
+	used := make(map[string]int)
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		if inCode {
+			continue
+		}
+
+		var indent, text string
+		switch {
+		case strings.HasPrefix(line, "## "):
+			text = strings.TrimPrefix(line, "## ")
+		case strings.HasPrefix(line, "### "):
+			indent, text = "  ", strings.TrimPrefix(line, "### ")
+		default:
+			continue
+		}
+
+		anchor := slug(text)
+		if n := used[anchor]; n > 0 {
+			used[anchor]++
+			anchor += fmt.Sprintf(suffix, n)
+		} else {
+			used[anchor]++
+		}
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", indent, text, anchor)
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\n## Contents\n\n" + b.String()
+}
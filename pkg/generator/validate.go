@@ -0,0 +1,43 @@
+package generator
+
+import "fmt"
+
+// Validate reports structural problems of an XRD: no versions, no served version,
+// duplicate version names, and versions without a schema or without a spec
+// property. Plain CRDs needn't have a spec, e.g. when they only hold data.
+// Generation only requires a version, so it documents XRDs with the other problems.
+func Validate(xrd *XRD) []error {
+	if len(xrd.Spec.Versions) == 0 {
+		return []error{fmt.Errorf("no versions found in XRD")}
+	}
+
+	var errs []error
+	served := false
+	seen := make(map[string]bool)
+	for _, v := range xrd.Spec.Versions {
+		served = served || v.Served
+		if seen[v.Name] {
+			errs = append(errs, fmt.Errorf("duplicate version %q", v.Name))
+		}
+		seen[v.Name] = true
+
+		schema := v.Schema.OpenAPIV3Schema
+		switch {
+		case schema.Type == "" && schema.Properties == nil:
+			errs = append(errs, fmt.Errorf("version %q has no openAPIV3Schema", v.Name))
+		case !hasProperty(schema, "spec") && !xrd.IsCRD():
+			errs = append(errs, fmt.Errorf("version %q has no spec property", v.Name))
+		}
+	}
+	if !served {
+		errs = append(errs, fmt.Errorf("no served version found in XRD"))
+	}
+
+	return errs
+}
+
+// hasProperty reports whether the schema declares the named property
+func hasProperty(schema OpenAPISchema, name string) bool {
+	_, ok := schema.Properties[name]
+	return ok
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const dataOnlySchema = `
+type: object
+properties:
+  data:
+    type: object
+    additionalProperties:
+      type: string
+`
+
+func TestValidateRequiresSpecOnXRDs(t *testing.T) {
+	xrd := testXRD(testVersion(t, "v1", dataOnlySchema))
+
+	errs := Validate(xrd)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "has no spec property") {
+		t.Errorf("Validate() = %v, want a missing spec error", errs)
+	}
+}
+
+func TestValidateAcceptsCRDsWithoutSpec(t *testing.T) {
+	crd := testXRD(testVersion(t, "v1", dataOnlySchema))
+	crd.Kind = KindCRD
+
+	if errs := Validate(crd); len(errs) != 0 {
+		t.Fatalf("Validate() = %v, want no errors", errs)
+	}
+
+	doc, err := New().Generate(crd, Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(doc, "spec: {}") {
+		t.Errorf("example of a CRD without spec sets spec:\n%s", doc)
+	}
+}
+
+func TestGenerateRendersInvalidXRDs(t *testing.T) {
+	unserved := testVersion(t, "v1alpha1", dataOnlySchema)
+	unserved.Served = false
+	xrd := testXRD(unserved)
+	xrd.Spec.Versions[0].Referenceable = false
+
+	if errs := Validate(xrd); len(errs) == 0 {
+		t.Fatal("Validate() reported no errors for an XRD without a served version or spec")
+	}
+	doc, err := New().Generate(xrd, Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v, want the XRD documented", err)
+	}
+	if !strings.Contains(doc, "v1alpha1") {
+		t.Errorf("output doesn't document the only version:\n%s", doc)
+	}
+
+	if _, err := New().Generate(testXRD(), Options{}); err == nil {
+		t.Error("Generate() of an XRD without versions succeeded")
+	}
+}
@@ -0,0 +1,125 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is one document of a multi-document YAML file
+type Document struct {
+	Kind string
+	Data []byte // The document on its own, re-encoded as YAML
+}
+
+// Split splits YAML into its "---" separated documents, skipping empty ones
+func Split(data []byte) ([]Document, error) {
+	var docs []Document
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		var header struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := node.Decode(&header); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+
+		doc, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode YAML document: %w", err)
+		}
+		docs = append(docs, Document{Kind: header.Kind, Data: doc})
+	}
+
+	return docs, nil
+}
+
+// Generate documents every XRD and Composition in a multi-document YAML file,
+// in file order, as one combined document. Documents of other kinds are skipped,
+// unless the file holds only that document.
+// Several XRDs documented as JSON form one array, and as CSV share one header.
+func Generate(data []byte, xrdOpts generator.Options, compOpts composition.Options) (string, error) {
+	docs, err := Split(data)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, doc := range docs {
+		kind := doc.Kind
+		if len(docs) == 1 && kind != "Composition" {
+			// A lone document goes to the XRD generator as before, which documents
+			// kind-less definitions and explains why other kinds can't be documented
+			kind = generator.KindXRD
+		}
+
+		var out string
+		switch kind {
+		case generator.KindXRD, generator.KindCRD:
+			out, err = generator.New().GenerateFromReader(bytes.NewReader(doc.Data), xrdOpts)
+		case "Composition":
+			if xrdOpts.Format != "" && xrdOpts.Format != "markdown" {
+				return "", fmt.Errorf("compositions can only be documented as markdown, not %s", xrdOpts.Format)
+			}
+			out, err = composition.New().GenerateFromReader(bytes.NewReader(doc.Data), compOpts)
+		default:
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.TrimRight(out, "\n")+"\n")
+	}
+
+	if len(parts) == 0 {
+		return "", fmt.Errorf("no XRD, CRD or Composition found")
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	switch xrdOpts.Format {
+	case "json":
+		return joinJSON(parts)
+	case "csv":
+		// Every part starts with the same header row
+		for i := 1; i < len(parts); i++ {
+			_, parts[i], _ = strings.Cut(parts[i], "\n")
+		}
+		return strings.Join(parts, ""), nil
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// joinJSON combines JSON documents into one array
+func joinJSON(parts []string) (string, error) {
+	docs := make([]json.RawMessage, len(parts))
+	for i, part := range parts {
+		docs[i] = json.RawMessage(part)
+	}
+	data, err := json.MarshalIndent(docs, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return string(data) + "\n", nil
+}
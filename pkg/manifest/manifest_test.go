@@ -0,0 +1,125 @@
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/michielvha/crossplane-docs/pkg/composition"
+	"github.com/michielvha/crossplane-docs/pkg/generator"
+)
+
+const twoXRDs = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xones.example.org
+spec:
+  group: example.org
+  names: {kind: XOne, plural: xones}
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region: {type: string}
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xtwos.example.org
+spec:
+  group: example.org
+  names: {kind: XTwo, plural: xtwos}
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size: {type: integer}
+`
+
+func TestGenerateJSONArray(t *testing.T) {
+	out, err := Generate([]byte(twoXRDs), generator.Options{Format: "json"}, composition.Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var docs []generator.Document
+	if err := json.Unmarshal([]byte(out), &docs); err != nil {
+		t.Fatalf("output isn't a JSON array: %v\n%s", err, out)
+	}
+	if len(docs) != 2 || docs[0].Kind != "XOne" || docs[1].Kind != "XTwo" {
+		t.Errorf("documents = %+v, want XOne and XTwo", docs)
+	}
+}
+
+func TestGenerateCSVSingleHeader(t *testing.T) {
+	out, err := Generate([]byte(twoXRDs), generator.Options{Format: "csv"}, composition.Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := "path,type,required,default,constraints,description\n" +
+		"spec.region,string,false,,,\n" +
+		"spec.size,integer,false,,,\n"
+	if out != want {
+		t.Errorf("Generate() =\n%s\nwant\n%s", out, want)
+	}
+	if n := strings.Count(out, "path,type"); n != 1 {
+		t.Errorf("output has %d header rows, want 1", n)
+	}
+}
+
+func TestGenerateLoneDocumentWithoutKind(t *testing.T) {
+	kindless := `
+spec:
+  group: example.org
+  names: {kind: XOne, plural: xones}
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region: {type: string}
+`
+	for name, data := range map[string]string{
+		"missing kind": kindless,
+		"empty kind":   "kind: \"\"\n" + kindless,
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := Generate([]byte(data), generator.Options{}, composition.Options{})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if !strings.Contains(out, "region") {
+				t.Errorf("Generate() =\n%s\nwant the region field documented", out)
+			}
+		})
+	}
+}
+
+func TestGenerateLoneDocumentOfOtherKind(t *testing.T) {
+	_, err := Generate([]byte("kind: ConfigMap\ndata: {a: b}\n"), generator.Options{}, composition.Options{})
+	if err == nil || !strings.Contains(err.Error(), "can't document a ConfigMap") {
+		t.Errorf("Generate() error = %v, want the generator's error for a ConfigMap", err)
+	}
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Writer writes generated documentation to stdout or to a destination file
+type Writer struct {
+	Stdout io.Writer // Receives documentation when no path is given
+	Log    io.Writer // Receives status messages, kept off Stdout so it can be piped
+}
+
+// New creates a Writer that writes to os.Stdout and logs to os.Stderr
+func New() *Writer {
+	return &Writer{Stdout: os.Stdout, Log: os.Stderr}
+}
+
+// Write writes content to path, or to the Stdout writer when path is empty.
+// Content always ends with a single trailing newline.
+func (w *Writer) Write(path, content string) error {
+	content = strings.TrimRight(content, "\n") + "\n"
+
+	if path == "" {
+		_, err := io.WriteString(w.Stdout, content)
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	_, err := fmt.Fprintf(w.Log, "Documentation generated successfully: %s\n", path)
+	return err
+}
@@ -0,0 +1,77 @@
+// Package section renders documents made of named template sections, so a
+// single section can also be rendered on its own
+package section
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Render executes the named sections of t in order. When only is set, just that
+// section is rendered, and its heading is dropped unless heading is true.
+func Render(t *template.Template, names []string, only string, heading bool, data interface{}) (string, error) {
+	if only == "" {
+		var buf bytes.Buffer
+		if err := Write(&buf, t, names, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	if !contains(names, only) {
+		return "", fmt.Errorf("unknown section %q: use one of %s", only, strings.Join(names, ", "))
+	}
+
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, only, data); err != nil {
+		return "", err
+	}
+
+	out := strings.TrimLeft(buf.String(), "\n")
+	if !heading && strings.HasPrefix(out, "#") {
+		_, out, _ = strings.Cut(out, "\n")
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return "", nil
+	}
+	return out + "\n", nil
+}
+
+// Write executes the named sections of t in order directly against w
+func Write(w io.Writer, t *template.Template, names []string, data interface{}) error {
+	for _, name := range names {
+		if err := t.ExecuteTemplate(w, name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeTableCell escapes characters that would break a markdown table cell
+func EscapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// CodeTableCell formats s as inline code inside a markdown table cell, using a
+// double-backtick span when s itself contains backticks
+func CodeTableCell(s string) string {
+	s = EscapeTableCell(s)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,157 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/michielvha/crossplane-docs/pkg/section"
+)
+
+// Page represents a generated per-kind documentation page
+type Page struct {
+	Group       string
+	Kind        string
+	Description string
+	Content     string
+	Metadata    interface{} // Written as a <kind>.meta.json sidecar when set
+	Source      string      // File the page was generated from, named in errors
+}
+
+// Options contains site layout options
+type Options struct {
+	SummaryLength int // truncate index descriptions to this many characters (0 = no limit)
+}
+
+// Write writes pages into root as <group>/<kind>.md, with an _index.md per group
+// listing its kinds. It returns the paths of all written files. Nothing is written
+// when two pages would be written to the same file.
+func Write(root string, pages []Page, opts Options) ([]string, error) {
+	sources := make(map[string]string, len(pages))
+	groups := make(map[string][]Page)
+	for _, p := range pages {
+		file := filepath.Join(p.Group, PageFilename(p.Kind))
+		if source, ok := sources[file]; ok {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", source, p.Source, filepath.Join(root, file))
+		}
+		sources[file] = p.Source
+		groups[p.Group] = append(groups[p.Group], p)
+	}
+
+	var written []string
+	for group, groupPages := range groups {
+		dir := filepath.Join(root, group)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return written, fmt.Errorf("failed to create directory: %w", err)
+		}
+
+		sort.Slice(groupPages, func(i, j int) bool {
+			return groupPages[i].Kind < groupPages[j].Kind
+		})
+
+		for i, p := range groupPages {
+			file := filepath.Join(dir, PageFilename(p.Kind))
+			content := Frontmatter(p.Kind, i+1) + p.Content
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				return written, fmt.Errorf("failed to write page: %w", err)
+			}
+			written = append(written, file)
+
+			if p.Metadata != nil {
+				sidecar := filepath.Join(dir, strings.ToLower(p.Kind)+".meta.json")
+				data, err := json.MarshalIndent(p.Metadata, "", "  ")
+				if err != nil {
+					return written, fmt.Errorf("failed to encode metadata: %w", err)
+				}
+				if err := os.WriteFile(sidecar, append(data, '\n'), 0o644); err != nil {
+					return written, fmt.Errorf("failed to write metadata: %w", err)
+				}
+				written = append(written, sidecar)
+			}
+		}
+
+		index := filepath.Join(dir, "_index.md")
+		if err := os.WriteFile(index, []byte(groupIndex(group, groupPages, opts)), 0o644); err != nil {
+			return written, fmt.Errorf("failed to write group index: %w", err)
+		}
+		written = append(written, index)
+	}
+
+	sort.Strings(written)
+	return written, nil
+}
+
+// PageFilename returns the page filename for a kind
+func PageFilename(kind string) string {
+	return strings.ToLower(kind) + ".md"
+}
+
+// Frontmatter renders Hugo/Docusaurus compatible YAML frontmatter
+func Frontmatter(title string, weight int) string {
+	return fmt.Sprintf("---\ntitle: %q\nweight: %d\n---\n\n", title, weight)
+}
+
+// groupIndex renders the _index.md section page for an API group
+func groupIndex(group string, pages []Page, opts Options) string {
+	var b strings.Builder
+
+	b.WriteString(Frontmatter(group, 1))
+	fmt.Fprintf(&b, "# %s\n\n", group)
+	b.WriteString("| Kind | Description |\n")
+	b.WriteString("|------|-------------|\n")
+	for _, p := range pages {
+		fmt.Fprintf(&b, "| [%s](%s) | %s |\n", p.Kind, PageFilename(p.Kind), section.EscapeTableCell(Summarize(p.Description, opts.SummaryLength)))
+	}
+
+	return b.String()
+}
+
+// IndexEntry is a generated page listed in a combined index
+type IndexEntry struct {
+	Group       string
+	Kind        string
+	Description string
+	Link        string // Path of the page relative to the index
+}
+
+// Index renders a combined index of generated pages, sorted by API group and kind
+func Index(entries []IndexEntry, opts Options) string {
+	sorted := append([]IndexEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Group != sorted[j].Group {
+			return sorted[i].Group < sorted[j].Group
+		}
+		return sorted[i].Kind < sorted[j].Kind
+	})
+
+	var b strings.Builder
+	b.WriteString("# API Reference\n\n")
+	b.WriteString("| Kind | API Group | Description |\n")
+	b.WriteString("|------|-----------|-------------|\n")
+	for _, e := range sorted {
+		fmt.Fprintf(&b, "| [%s](%s) | `%s` | %s |\n", e.Kind, e.Link, e.Group, section.EscapeTableCell(Summarize(e.Description, opts.SummaryLength)))
+	}
+
+	return b.String()
+}
+
+// Summarize collapses a description onto a single line and, when maxLen is positive,
+// truncates it to at most maxLen characters at a word boundary, ending in an ellipsis
+func Summarize(description string, maxLen int) string {
+	summary := strings.Join(strings.Fields(description), " ")
+
+	runes := []rune(summary)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return summary
+	}
+
+	// Leave room for the ellipsis and cut at the last space that fits
+	cut := string(runes[:maxLen-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
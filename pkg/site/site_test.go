@@ -0,0 +1,111 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRejectsDuplicatePages(t *testing.T) {
+	root := t.TempDir()
+	pages := []Page{
+		{Group: "example.org", Kind: "XNetwork", Source: "a/network.yaml"},
+		{Group: "example.org", Kind: "XDatabase", Source: "a/database.yaml"},
+		{Group: "example.org", Kind: "XNetwork", Source: "b/network.yaml"},
+	}
+
+	_, err := Write(root, pages, Options{})
+	if err == nil {
+		t.Fatal("Write() of two pages for one kind succeeded")
+	}
+	for _, want := range []string{"a/network.yaml", "b/network.yaml", filepath.Join(root, "example.org", "xnetwork.md")} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Write() error = %v, want it to name %s", err, want)
+		}
+	}
+
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Errorf("Write() wrote %d entries despite the collision", len(entries))
+	}
+}
+
+func TestWriteTwoGroups(t *testing.T) {
+	root := t.TempDir()
+	pages := []Page{
+		{Group: "storage.example.org", Kind: "XBucket", Description: "An object\nstore bucket", Content: "# XBucket\n"},
+		{Group: "network.example.org", Kind: "XSubnet", Description: "A subnet | or a range", Content: "# XSubnet\n"},
+		{Group: "network.example.org", Kind: "XNetwork", Description: "A network", Content: "# XNetwork\n"},
+	}
+
+	written, err := Write(root, pages, Options{})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got []string
+	for _, file := range written {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{
+		"network.example.org/_index.md",
+		"network.example.org/xnetwork.md",
+		"network.example.org/xsubnet.md",
+		"storage.example.org/_index.md",
+		"storage.example.org/xbucket.md",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Write() wrote\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	page, err := os.ReadFile(filepath.Join(root, "network.example.org", "xsubnet.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Frontmatter("XSubnet", 2) + "# XSubnet\n"; string(page) != want {
+		t.Errorf("xsubnet.md = %q, want %q", page, want)
+	}
+
+	indexes := map[string]string{
+		"network.example.org": Frontmatter("network.example.org", 1) +
+			"# network.example.org\n\n" +
+			"| Kind | Description |\n" +
+			"|------|-------------|\n" +
+			"| [XNetwork](xnetwork.md) | A network |\n" +
+			"| [XSubnet](xsubnet.md) | A subnet \\| or a range |\n",
+		"storage.example.org": Frontmatter("storage.example.org", 1) +
+			"# storage.example.org\n\n" +
+			"| Kind | Description |\n" +
+			"|------|-------------|\n" +
+			"| [XBucket](xbucket.md) | An object store bucket |\n",
+	}
+	for group, want := range indexes {
+		index, err := os.ReadFile(filepath.Join(root, group, "_index.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(index) != want {
+			t.Errorf("%s/_index.md =\n%s\nwant\n%s", group, index, want)
+		}
+	}
+}
+
+func TestIndexEscapesDescriptions(t *testing.T) {
+	got := Index([]IndexEntry{
+		{Group: "b.example.org", Kind: "XQueue", Description: "A queue", Link: "b.example.org/xqueue.md"},
+		{Group: "a.example.org", Kind: "XTopic", Description: "Either a | b", Link: "a.example.org/xtopic.md"},
+	}, Options{})
+
+	want := "# API Reference\n\n" +
+		"| Kind | API Group | Description |\n" +
+		"|------|-----------|-------------|\n" +
+		"| [XTopic](a.example.org/xtopic.md) | `a.example.org` | Either a \\| b |\n" +
+		"| [XQueue](b.example.org/xqueue.md) | `b.example.org` | A queue |\n"
+	if got != want {
+		t.Errorf("Index() =\n%s\nwant\n%s", got, want)
+	}
+}